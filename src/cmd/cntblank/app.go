@@ -14,7 +14,9 @@ type Application struct {
 	collector *FileCollector
 	reports   []Report
 	writer    ReportWriter
+	option    *ProfileOption
 	logfields log.Fields
+	opener    *archiveOpener // keeps tar stream while iterating files
 }
 
 // Run application main logic.
@@ -30,8 +32,13 @@ func (a *Application) Run(pathList []string, dialect *csvhelper.FileDialect) err
 		files = a.collector.files
 	}
 	a.reports = make([]Report, len(files))
+	a.opener = new(archiveOpener)
+	defer func() {
+		a.opener.Close()
+		a.opener = nil
+	}()
 	for i, file := range files {
-		report := newReport(file)
+		report := newReport(file, a.option)
 		err := a.process(report, dialect)
 		if err != nil {
 			log.Errorf("[%d] error while processing %s: %v", i+1, file.path, err)
@@ -43,13 +50,30 @@ func (a *Application) Run(pathList []string, dialect *csvhelper.FileDialect) err
 }
 
 func (a *Application) process(report *Report, dialect *csvhelper.FileDialect) error {
-	reader, err := OpenFile(report.Path, dialect)
+	reader, err := openFile(report.Path, dialect, a.opener)
 	if err != nil {
 		return err
 	}
 	defer reader.Close()
 
-	return a.cntblank(report, reader, dialect.HasHeader)
+	// Dialect may differ by file when it is sniffed.
+	report.dialect = reader.dialect
+	report.Encoding = reader.encoding
+	report.HasBOM = reader.hasBOM
+	if reader.csvReader != nil {
+		report.Delimiter = string(reader.dialect.Comma)
+		report.Quoting = reader.dialect.Quoting
+	}
+	if err := a.cntblank(report, reader, reader.dialect.HasHeader); err != nil {
+		return err
+	}
+	// Checksum is calculated on reading to avoid reading the file twice.
+	if report.Path != "" {
+		if md5hex, err := reader.Checksum(); err == nil {
+			report.MD5hex = md5hex
+		}
+	}
+	return nil
 }
 
 // Run application core logic.
@@ -82,12 +106,13 @@ func (a *Application) cntblank(report *Report, reader *Reader, hasHeader bool) e
 			}
 			continue
 		}
-		nullCount := report.parseRecord(record)
+		nullCount := report.parseRecordAt(record, reader.recordLine)
 		if nullCount > 0 {
 			logger.Debugf("line #%d has %d fields with %d NULL(s).",
-				reader.line, len(record), nullCount)
+				reader.recordLine, len(record), nullCount)
 		}
 	}
+	report.summarize()
 	logger.Infof("get %d records with %d columns",
 		report.Records, len(report.Fields))
 	return nil
@@ -99,7 +124,7 @@ func (a *Application) putReport() error {
 }
 
 // newApplication creates `Application` object to set some options.
-func newApplication(recursive bool, writer io.Writer, format string, dialect *csvhelper.FileDialect) (a *Application, err error) {
+func newApplication(recursive bool, writer io.Writer, format string, dialect *csvhelper.FileDialect, option *ProfileOption) (a *Application, err error) {
 	f := CSV // default output format is CSV
 	if format != "" {
 		f = formatFrom(format)
@@ -107,7 +132,13 @@ func newApplication(recursive bool, writer io.Writer, format string, dialect *cs
 			return nil, fmt.Errorf("unknown format %q", format)
 		}
 	}
+	if option == nil {
+		option = NewProfileOption()
+	} else if err := option.Validate(); err != nil {
+		return nil, err
+	}
 	a = new(Application)
+	a.option = option
 	a.collector = newFileCollector(recursive, []string{
 		".csv",
 		".tsv",
@@ -2,6 +2,9 @@ package main
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"csvhelper"
@@ -13,7 +16,7 @@ A,B
 C,D
 `)
 	buffer := &bytes.Buffer{}
-	app, _ := newApplication(false, buffer, "", &csvhelper.FileDialect{})
+	app, _ := newApplication(false, buffer, "", &csvhelper.FileDialect{}, nil)
 	dialect := &csvhelper.FileDialect{
 		Comma:     ',',
 		HasHeader: true,
@@ -43,7 +46,7 @@ PI,3.1415926535897932384
 ネイピア数,2.718281828459045235360287471352
 `)
 	buffer := &bytes.Buffer{}
-	app, _ := newApplication(false, buffer, "", &csvhelper.FileDialect{})
+	app, _ := newApplication(false, buffer, "", &csvhelper.FileDialect{}, nil)
 	dialect := &csvhelper.FileDialect{
 		Comma:     ',',
 		HasHeader: true,
@@ -108,7 +111,7 @@ PI ,3.1415926535897932384 ,"blank after value "
  PI , 3.1415926535897932384 ," blank both of value "
 `)
 	buffer := &bytes.Buffer{}
-	app, _ := newApplication(false, buffer, "", &csvhelper.FileDialect{})
+	app, _ := newApplication(false, buffer, "", &csvhelper.FileDialect{}, nil)
 	dialect := &csvhelper.FileDialect{
 		Comma:     ',',
 		HasHeader: true,
@@ -157,3 +160,104 @@ PI ,3.1415926535897932384 ,"blank after value "
 		}
 	}
 }
+
+func TestBOM(t *testing.T) {
+	for _, hasHeader := range []bool{true, false} {
+		input := []byte("\xef\xbb\xbfid,value\n1,A\n2,B\n")
+		if !hasHeader {
+			input = []byte("\xef\xbb\xbf1,A\n2,B\n")
+		}
+		app, _ := newApplication(false, &bytes.Buffer{}, "", &csvhelper.FileDialect{}, nil)
+		dialect := &csvhelper.FileDialect{
+			Comma:     ',',
+			HasHeader: hasHeader,
+		}
+		report := new(Report)
+		reader, err := NewReader(bytes.NewBuffer(input), dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = app.cntblank(report, reader, dialect.HasHeader)
+		if err != nil {
+			t.Error(err)
+		}
+		if hasHeader && report.Fields[0].Name != "id" {
+			t.Errorf("BOM should be stripped from header name: %q", report.Fields[0].Name)
+		}
+		if report.Fields[0].InferredType != IntegerType {
+			t.Errorf("first column should be integer: %q", report.Fields[0].InferredType)
+		}
+	}
+}
+
+func TestSniffDialect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cntblank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tc := range []struct {
+		fname     string
+		input     string
+		hasHeader bool
+		delimiter string
+		header    bool
+		records   int
+	}{
+		{"a.csv", "id,name\n1,A\n2,B\n", true, ",", true, 2},
+		{"b.tsv", "id\tname\n1\tA\n2\tB\n", true, "\t", true, 2},
+		{"c.txt", "1|A\n2|B\n3|C\n", true, "|", false, 3},
+		{"d.csv", "id,name\n1,A\n2,B\n", false, ",", false, 3},
+	} {
+		path := filepath.Join(dir, tc.fname)
+		if err := ioutil.WriteFile(path, []byte(tc.input), 0644); err != nil {
+			t.Fatal(err)
+		}
+		app, _ := newApplication(false, &bytes.Buffer{}, "", &csvhelper.FileDialect{}, nil)
+		dialect := &csvhelper.FileDialect{
+			Comma:     '\t',
+			HasHeader: tc.hasHeader,
+			Sniff:     true,
+		}
+		report := newReport(File{path: path}, app.option)
+		if err := app.process(report, dialect); err != nil {
+			t.Error(err)
+		}
+		if report.Delimiter != tc.delimiter {
+			t.Errorf("%s: delimiter should be %q, but %q", tc.fname, tc.delimiter, report.Delimiter)
+		}
+		if report.Quoting != csvhelper.QuoteNone {
+			t.Errorf("%s: quoting should be none, but %q", tc.fname, report.Quoting)
+		}
+		if report.HasHeader != tc.header {
+			t.Errorf("%s: header should be %t", tc.fname, tc.header)
+		}
+		if report.Records != tc.records {
+			t.Errorf("%s: records should be %d, but %d", tc.fname, tc.records, report.Records)
+		}
+	}
+}
+
+func TestOutlierLine(t *testing.T) {
+	input := "value,memo\n1,\"multi\nline\"\n\n2,a\n3,b\n4,c\n5,d\n6,e\n7,f\n8,g\n9,h\n1000,i\n"
+	app, _ := newApplication(false, &bytes.Buffer{}, "", &csvhelper.FileDialect{}, nil)
+	dialect := &csvhelper.FileDialect{
+		Comma:     ',',
+		HasHeader: true,
+	}
+	report := newReport(File{}, app.option)
+	reader, err := NewReader(bytes.NewBufferString(input), dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.cntblank(report, reader, dialect.HasHeader); err != nil {
+		t.Error(err)
+	}
+	outliers := report.Fields[0].Outliers
+	if len(outliers) != 1 || outliers[0].Value != 1000 {
+		t.Fatalf("1000 should be outlier: %v", outliers)
+	}
+	if outliers[0].Line != 13 {
+		t.Errorf("outlier should be on physical line 13, but %d", outliers[0].Line)
+	}
+}
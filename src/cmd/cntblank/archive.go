@@ -0,0 +1,266 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// archiveSeparator separates path of archive and name of its member,
+// such as "bundle.zip!/data/a.csv".
+const archiveSeparator = "!/"
+
+// archiveMember is a regular file in archive.
+type archiveMember struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// archiveFormat returns "zip" or "tar" by extension of path, including
+// compressed tar such as ".tar.gz". It returns empty string otherwise.
+func archiveFormat(p string) string {
+	lower := strings.ToLower(p)
+	if strings.HasSuffix(lower, ".zip") {
+		return "zip"
+	}
+	if strings.HasSuffix(trimCompressionExt(lower), ".tar") || strings.HasSuffix(lower, ".tgz") {
+		return "tar"
+	}
+	return ""
+}
+
+// splitArchivePath splits path into archive and its member.
+// Member is empty when path is not in archive, where the separator may
+// appear in real directory names such as "backup!/a.csv". So path is
+// split only after archive which exists as a regular file.
+func splitArchivePath(p string) (archive, member string) {
+	for i := 0; i < len(p); {
+		j := strings.Index(p[i:], archiveSeparator)
+		if j < 0 {
+			break
+		}
+		archive = p[:i+j]
+		if archiveFormat(archive) != "" {
+			if info, err := os.Stat(archive); err == nil && info.Mode().IsRegular() {
+				return archive, p[i+j+len(archiveSeparator):]
+			}
+		}
+		i += j + len(archiveSeparator)
+	}
+	return p, ""
+}
+
+// zipMemberName returns name of zip member in UTF-8. Zip files created on
+// Japanese Windows have names in Shift_JIS without any flag.
+func zipMemberName(f *zip.File) string {
+	if utf8.ValidString(f.Name) {
+		return f.Name
+	}
+	name, err := japanese.ShiftJIS.NewDecoder().String(f.Name)
+	if err != nil {
+		return f.Name
+	}
+	return name
+}
+
+// archiveMembers returns regular files in archive.
+func archiveMembers(archive string) (members []archiveMember, err error) {
+	switch archiveFormat(archive) {
+	case "zip":
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			members = append(members, archiveMember{
+				name:    zipMemberName(f),
+				size:    int64(f.UncompressedSize64),
+				modTime: f.ModTime(),
+			})
+		}
+	case "tar":
+		s, err := openTarStream(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer s.Close()
+		for {
+			h, err := s.tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			if h.Typeflag != tar.TypeReg && h.Typeflag != tar.TypeRegA {
+				continue
+			}
+			members = append(members, archiveMember{
+				name:    h.Name,
+				size:    h.Size,
+				modTime: h.ModTime,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("%s is not an archive", archive)
+	}
+	return members, nil
+}
+
+// archiveOpener opens files and members of archives given by paths. It
+// keeps the tar stream positioned after the member read last, so that
+// members collected in order of archive are decompressed in a single pass.
+// It is not safe for concurrent use, and the owner closes it after reading
+// all files. Nil opener does not keep any stream.
+type archiveOpener struct {
+	parked *tarStream
+}
+
+// open opens file or member in archive given by path.
+func (o *archiveOpener) open(p string) (io.ReadCloser, error) {
+	if archive, member := splitArchivePath(p); member != "" {
+		return o.openMember(archive, member)
+	}
+	return os.Open(p)
+}
+
+// Close closes the kept stream.
+func (o *archiveOpener) Close() error {
+	if o == nil || o.parked == nil {
+		return nil
+	}
+	err := o.parked.Close()
+	o.parked = nil
+	return err
+}
+
+// openMember opens member in archive as a stream.
+func (o *archiveOpener) openMember(archive, member string) (io.ReadCloser, error) {
+	switch archiveFormat(archive) {
+	case "zip":
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if zipMemberName(f) != member {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				zr.Close()
+				return nil, err
+			}
+			return readCloser{rc, multiCloser{zr, rc}}, nil
+		}
+		zr.Close()
+	case "tar":
+		return o.openTarMember(archive, member)
+	default:
+		return nil, fmt.Errorf("%s is not an archive", archive)
+	}
+	return nil, fmt.Errorf("%s has no member %s", archive, member)
+}
+
+// tarStream is a decompressed stream of tar archive.
+type tarStream struct {
+	archive string
+	tr      *tar.Reader
+	closer  io.Closer
+}
+
+func openTarStream(archive string) (*tarStream, error) {
+	fp, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := decompress(fp)
+	if err != nil {
+		fp.Close()
+		return nil, err
+	}
+	return &tarStream{archive, tar.NewReader(r), multiCloser{fp, r}}, nil
+}
+
+func (s *tarStream) Close() error {
+	return s.closer.Close()
+}
+
+// seek advances the stream to the member.
+func (s *tarStream) seek(member string) error {
+	for {
+		h, err := s.tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s has no member %s", s.archive, member)
+		} else if err != nil {
+			return err
+		}
+		if h.Name == member {
+			return nil
+		}
+	}
+}
+
+// openTarMember opens member in tar archive from the kept stream, or from
+// the beginning of archive when the member is behind it.
+func (o *archiveOpener) openTarMember(archive, member string) (io.ReadCloser, error) {
+	if o != nil && o.parked != nil {
+		s := o.parked
+		o.parked = nil
+		if s.archive == archive && s.seek(member) == nil {
+			return tarMemberReader{s, o}, nil
+		}
+		s.Close()
+	}
+	s, err := openTarStream(archive)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.seek(member); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return tarMemberReader{s, o}, nil
+}
+
+// tarMemberReader reads a member, and returns the stream to the opener on
+// close, or closes it without opener.
+type tarMemberReader struct {
+	s *tarStream
+	o *archiveOpener
+}
+
+func (r tarMemberReader) Read(p []byte) (int, error) {
+	return r.s.tr.Read(p)
+}
+
+func (r tarMemberReader) Close() error {
+	if r.o == nil {
+		return r.s.Close()
+	}
+	r.o.Close()
+	r.o.parked = r.s
+	return nil
+}
+
+// openPath opens file or member in archive given by path.
+func openPath(p string) (io.ReadCloser, error) {
+	var o *archiveOpener
+	return o.open(p)
+}
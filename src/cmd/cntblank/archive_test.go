@@ -0,0 +1,240 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"csvhelper"
+)
+
+var archiveTestMembers = []struct {
+	name    string
+	content string
+}{
+	{"data/a.csv", "a,b\n1,2\n"},
+	{"data/b.tsv", "c\td\n3\t\n"},
+	{"data/readme.txt", "hello\n"},
+	{"data/.hidden.csv", "x\n"},
+	{"\x93\x8c\x8b\x9e.csv", "e\n5\n"}, // "東京.csv" in Shift_JIS.
+}
+
+func writeTestZip(t *testing.T, path string) {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	_, err := zw.Create("data/")
+	require.Nil(t, err)
+	for _, m := range archiveTestMembers {
+		w, err := zw.Create(m.name)
+		require.Nil(t, err)
+		io.WriteString(w, m.content)
+	}
+	require.Nil(t, zw.Close())
+	require.Nil(t, ioutil.WriteFile(path, b.Bytes(), 0644))
+}
+
+func writeTestTarGz(t *testing.T, path string) {
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gw)
+	require.Nil(t, tw.WriteHeader(&tar.Header{Name: "data/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for _, m := range archiveTestMembers[:4] {
+		require.Nil(t, tw.WriteHeader(&tar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.content))}))
+		io.WriteString(tw, m.content)
+	}
+	require.Nil(t, tw.Close())
+	require.Nil(t, gw.Close())
+	require.Nil(t, ioutil.WriteFile(path, b.Bytes(), 0644))
+}
+
+func TestArchiveFormat(t *testing.T) {
+	for _, tt := range []struct {
+		path     string
+		expected string
+	}{
+		{"bundle.zip", "zip"},
+		{"bundle.ZIP", "zip"},
+		{"bundle.tar", "tar"},
+		{"bundle.tar.gz", "tar"},
+		{"bundle.tgz", "tar"},
+		{"bundle.tar.xz", "tar"},
+		{"data.csv.gz", ""},
+		{"data.xlsx", ""},
+	} {
+		assert.Equal(t, tt.expected, archiveFormat(tt.path), tt.path)
+	}
+}
+
+func TestSplitArchivePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cntblank")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	zipPath := filepath.Join(dir, "bundle.zip")
+	writeTestZip(t, zipPath)
+	// Directories whose names contain the separator.
+	for _, d := range []string{"backup!", "old.zip!"} {
+		require.Nil(t, os.Mkdir(filepath.Join(dir, d), 0755))
+		require.Nil(t, ioutil.WriteFile(filepath.Join(dir, d, "a.csv"), []byte("a\n1\n"), 0644))
+	}
+	for _, tt := range []struct {
+		path    string
+		archive string
+		member  string
+	}{
+		{zipPath + "!/data/a.csv", zipPath, "data/a.csv"},
+		{filepath.Join(dir, "a.csv"), filepath.Join(dir, "a.csv"), ""},
+		{filepath.Join(dir, "backup!", "a.csv"), filepath.Join(dir, "backup!", "a.csv"), ""},
+		{filepath.Join(dir, "old.zip!", "a.csv"), filepath.Join(dir, "old.zip!", "a.csv"), ""},
+		{filepath.Join(dir, "none.zip!", "a.csv"), filepath.Join(dir, "none.zip!", "a.csv"), ""},
+	} {
+		archive, member := splitArchivePath(tt.path)
+		assert.Equal(t, tt.archive, archive, tt.path)
+		assert.Equal(t, tt.member, member, tt.path)
+	}
+	reader, err := OpenFile(filepath.Join(dir, "backup!", "a.csv"), &csvhelper.FileDialect{Comma: ','})
+	require.Nil(t, err)
+	record, err := reader.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a"}, record)
+	reader.Close()
+}
+
+func TestCollectArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cntblank")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	zipPath := filepath.Join(dir, "bundle.zip")
+	writeTestZip(t, zipPath)
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	writeTestTarGz(t, tarPath)
+
+	c := newFileCollector(false, []string{".csv", ".tsv"})
+	require.Nil(t, c.CollectAll([]string{zipPath, tarPath}))
+	var paths []string
+	for _, f := range c.files {
+		paths = append(paths, f.path)
+	}
+	assert.Equal(t, []string{
+		zipPath + "!/data/a.csv",
+		zipPath + "!/data/b.tsv",
+		zipPath + "!/東京.csv",
+		tarPath + "!/data/a.csv",
+		tarPath + "!/data/b.tsv",
+	}, paths)
+	f := c.files[0]
+	assert.Equal(t, "a.csv", f.Name())
+	assert.Equal(t, zipPath+"!/data", f.Dir())
+	assert.Equal(t, int64(8), f.size)
+	sum := md5.Sum([]byte("a,b\n1,2\n"))
+	for _, f := range []File{c.files[0], c.files[3]} {
+		md5hex, err := f.Checksum()
+		assert.Nil(t, err)
+		assert.Equal(t, hex.EncodeToString(sum[:]), md5hex)
+	}
+
+	// Archive in directory is traversed only in recursive mode.
+	c = newFileCollector(false, []string{".csv"})
+	require.Nil(t, c.Collect(dir))
+	assert.Equal(t, 0, len(c.files))
+	c = newFileCollector(true, []string{".csv"})
+	require.Nil(t, c.Collect(dir))
+	assert.Equal(t, 3, len(c.files))
+}
+
+func TestArchiveReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cntblank")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	zipPath := filepath.Join(dir, "bundle.zip")
+	writeTestZip(t, zipPath)
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	writeTestTarGz(t, tarPath)
+	for _, path := range []string{
+		zipPath + "!/data/b.tsv",
+		tarPath + "!/data/b.tsv",
+	} {
+		sum := md5.Sum([]byte("c\td\n3\t\n"))
+		reader, err := OpenFile(path, &csvhelper.FileDialect{Comma: '\t'})
+		require.Nil(t, err, path)
+		for _, expected := range [][]string{{"c", "d"}, {"3", ""}} {
+			record, err := reader.Read()
+			assert.Nil(t, err, path)
+			assert.Equal(t, expected, record, path)
+		}
+		_, err = reader.Read()
+		assert.Equal(t, io.EOF, err, path)
+		md5hex, err := reader.Checksum()
+		assert.Nil(t, err, path)
+		assert.Equal(t, hex.EncodeToString(sum[:]), md5hex, path)
+		reader.Close()
+	}
+	_, err = OpenFile(zipPath+"!/data/none.csv", &csvhelper.FileDialect{})
+	assert.NotNil(t, err)
+}
+
+func TestOpenTarMember(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cntblank")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	writeTestTarGz(t, tarPath)
+	opener := new(archiveOpener)
+	var stream *tarStream
+	// Following members are read from the kept stream, and preceding
+	// member is read from the beginning again.
+	for i, tt := range []struct {
+		member string
+		reused bool
+	}{
+		{"data/a.csv", false},
+		{"data/b.tsv", true},
+		{"data/.hidden.csv", true},
+		{"data/a.csv", false},
+	} {
+		rc, err := opener.openMember(tarPath, tt.member)
+		require.Nil(t, err, tt.member)
+		s := rc.(tarMemberReader).s
+		assert.Equal(t, tt.reused, s == stream, "#%d %s", i, tt.member)
+		b, err := ioutil.ReadAll(rc)
+		assert.Nil(t, err)
+		assert.Equal(t, archiveTestMembers[indexOfTestMember(tt.member)].content, string(b))
+		rc.Close()
+		stream = s
+	}
+	_, err = opener.openMember(tarPath, "data/none.csv")
+	assert.NotNil(t, err)
+	assert.Nil(t, opener.parked, "stream should not be kept after failure")
+
+	rc, err := opener.open(tarPath + "!/data/b.tsv")
+	require.Nil(t, err)
+	rc.Close()
+	assert.NotNil(t, opener.parked)
+	assert.Nil(t, opener.Close())
+	assert.Nil(t, opener.parked, "stream should be closed with opener")
+
+	// Without opener, stream is closed with member.
+	rc, err = openPath(tarPath + "!/data/a.csv")
+	require.Nil(t, err)
+	rc.Close()
+	assert.Nil(t, rc.(tarMemberReader).o)
+}
+
+func indexOfTestMember(name string) int {
+	for i, m := range archiveTestMembers {
+		if m.name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	valid "github.com/asaskevich/govalidator"
+)
+
+// CharacterProfile counts cells by classes of characters they contain,
+// which helps to decide normalization rules such as NFKC.
+type CharacterProfile struct {
+	FullWidth     int `json:"fullWidth"`     // cells containing full-width characters
+	HalfWidthKana int `json:"halfWidthKana"` // cells containing half-width katakana
+	Hiragana      int `json:"hiragana"`      // cells containing hiragana
+	Kanji         int `json:"kanji"`         // cells containing kanji
+	ASCII         int `json:"ascii"`         // cells consisting of ASCII characters only
+	Digits        int `json:"digits"`        // cells consisting of digits only
+	Control       int `json:"control"`       // cells containing control characters
+	Replacement   int `json:"replacement"`   // cells containing U+FFFD or invalid bytes
+}
+
+func (p *CharacterProfile) add(s string) {
+	if valid.IsFullWidth(s) {
+		p.FullWidth++
+	}
+	var halfWidthKana, hiragana, kanji, control, replacement bool
+	ascii, digits := true, true
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			ascii = false
+		}
+		if r < '0' || r > '9' {
+			digits = false
+		}
+		switch {
+		case r >= '･' && r <= 'ﾟ':
+			halfWidthKana = true
+		case unicode.Is(unicode.Hiragana, r):
+			hiragana = true
+		case unicode.Is(unicode.Han, r):
+			kanji = true
+		case unicode.IsControl(r):
+			control = true
+		case r == utf8.RuneError:
+			replacement = true
+		}
+	}
+	for _, c := range []struct {
+		found bool
+		count *int
+	}{
+		{halfWidthKana, &p.HalfWidthKana},
+		{hiragana, &p.Hiragana},
+		{kanji, &p.Kanji},
+		{ascii, &p.ASCII},
+		{digits, &p.Digits},
+		{control, &p.Control},
+		{replacement, &p.Replacement},
+	} {
+		if c.found {
+			*c.count++
+		}
+	}
+}
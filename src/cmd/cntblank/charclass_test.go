@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCharacterProfile(t *testing.T) {
+	a := assert.New(t)
+	p := CharacterProfile{}
+	for _, s := range []string{
+		"010006",
+		"北海道",
+		"ﾎｯｶｲﾄﾞｳ",
+		"さっぽろし",
+		"Ｓａｐｐｏｒｏ",
+		"Sapporo-shi",
+		"a\x01b",
+		"broken\xff",
+		"�",
+	} {
+		p.add(s)
+	}
+	a.Equal(CharacterProfile{
+		FullWidth:     6, // govalidator treats control characters and U+FFFD as full width
+		HalfWidthKana: 1,
+		Hiragana:      1,
+		Kanji:         1,
+		ASCII:         3,
+		Digits:        1,
+		Control:       1,
+		Replacement:   2,
+	}, p)
+}
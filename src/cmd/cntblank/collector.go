@@ -64,6 +64,10 @@ func (c *FileCollector) Collect(path string) error {
 				if !c.recursive && path != p {
 					return filepath.SkipDir
 				}
+			} else if c.recursive && archiveFormat(p) != "" {
+				if err := c.collectArchive(p); err != nil {
+					log.Errorf("archive %s: %v", p, err)
+				}
 			} else if c.isTarget(p) {
 				return c.dispatch(p, info)
 			}
@@ -72,12 +76,34 @@ func (c *FileCollector) Collect(path string) error {
 		if err != nil {
 			return err
 		}
+	} else if archiveFormat(path) != "" {
+		log.Infof("walk archive: %s", path)
+		return c.collectArchive(path)
 	} else if c.isTarget(path) {
 		return c.dispatch(path, fileInfo)
 	}
 	return nil
 }
 
+// collectArchive collects members in archive like files in directory.
+func (c *FileCollector) collectArchive(archive string) error {
+	members, err := archiveMembers(archive)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if !c.isTarget(m.name) {
+			continue
+		}
+		c.files = append(c.files, File{
+			path:    archive + archiveSeparator + m.name,
+			size:    m.size,
+			modTime: m.modTime,
+		})
+	}
+	return nil
+}
+
 func (c *FileCollector) dispatch(p string, fileInfo os.FileInfo) error {
 	t := File{
 		path:    p,
@@ -99,7 +125,8 @@ func (c *FileCollector) isTarget(p string) bool {
 		return true
 	}
 	// If file extentions are set, filter them.
-	ext := strings.ToLower(path.Ext(p))
+	// Compressed file is filtered by double extension such as ".csv.gz".
+	ext := strings.ToLower(path.Ext(trimCompressionExt(p)))
 	for _, fmt := range c.extentions {
 		if ext == fmt {
 			return true
@@ -119,11 +146,12 @@ func (f *File) Dir() string {
 }
 
 // Checksum returns MD5 checksum as hex string.
+// Checksum of member in archive is calculated on its contents.
 func (f *File) Checksum() (string, error) {
 	if f.path == "" {
 		return "", fmt.Errorf("path is empty")
 	}
-	fp, err := os.Open(f.path)
+	fp, err := openPath(f.path)
 	if err != nil {
 		return "", err
 	}
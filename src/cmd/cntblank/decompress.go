@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compression is a compression format with its file extension and magic
+// bytes at the beginning of a stream.
+type compression struct {
+	name      string
+	extension string
+	magic     []byte
+	reader    func(r io.Reader) (io.ReadCloser, error)
+}
+
+var compressions = []compression{
+	{"gzip", ".gz", []byte{0x1f, 0x8b}, func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	}},
+	{"bzip2", ".bz2", []byte("BZh"), func(r io.Reader) (io.ReadCloser, error) {
+		return nopCloser{bzip2.NewReader(r)}, nil
+	}},
+	{"xz", ".xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, func(r io.Reader) (io.ReadCloser, error) {
+		x, err := xz.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return nopCloser{x}, nil
+	}},
+	{"zstd", ".zst", []byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.ReadCloser, error) {
+		z, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return z.IOReadCloser(), nil
+	}},
+}
+
+type nopCloser struct {
+	io.Reader
+}
+
+func (nopCloser) Close() error { return nil }
+
+// trimCompressionExt returns path without extension of compression,
+// such as "data.csv" for "data.csv.gz".
+func trimCompressionExt(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, c := range compressions {
+		if ext == c.extension {
+			return path[:len(path)-len(ext)]
+		}
+	}
+	return path
+}
+
+// decompress returns a reader which decompresses r as a stream when r
+// starts with magic bytes of gzip, bzip2, xz or zstd, and a name of the
+// compression. Otherwise, it returns a reader of r as it is.
+func decompress(r io.Reader) (io.ReadCloser, string, error) {
+	br := bufio.NewReader(r)
+	for _, c := range compressions {
+		magic, _ := br.Peek(len(c.magic))
+		if bytes.Equal(magic, c.magic) {
+			dr, err := c.reader(br)
+			if err != nil {
+				return nil, "", err
+			}
+			return dr, c.name, nil
+		}
+	}
+	return nopCloser{br}, "", nil
+}
+
+// multiCloser closes all closers in reverse order, which are decompressor
+// and underlying file.
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() (err error) {
+	for i := len(m) - 1; i >= 0; i-- {
+		if e := m[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+
+	"csvhelper"
+)
+
+const compressTestData = "a,b\n1,2\n"
+
+func compressTestBytes(t *testing.T, compression string) []byte {
+	var b bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch compression {
+	case "gzip":
+		w = gzip.NewWriter(&b)
+	case "bzip2":
+		// Standard library has no bzip2 writer.
+		return []byte("\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xbf\x87\x40\x7f\x00\x00\x03\x59\x00\x00\x10\x00\x04\x30\x00\x30\x00\x20\x00\x30\xc0\x08\x69\xb2\x88\x23\x27\x8b\xb9\x22\x9c\x28\x48\x5f\xc3\xa0\x3f\x80")
+	case "xz":
+		w, err = xz.NewWriter(&b)
+	case "zstd":
+		w, err = zstd.NewWriter(&b)
+	default:
+		return []byte(compressTestData)
+	}
+	require.Nil(t, err)
+	_, err = io.WriteString(w, compressTestData)
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	return b.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	for _, compression := range []string{"gzip", "bzip2", "xz", "zstd", ""} {
+		r, name, err := decompress(bytes.NewReader(compressTestBytes(t, compression)))
+		require.Nil(t, err, compression)
+		assert.Equal(t, compression, name)
+		b, err := ioutil.ReadAll(r)
+		assert.Nil(t, err, compression)
+		assert.Equal(t, compressTestData, string(b), compression)
+		assert.Nil(t, r.Close())
+	}
+	_, _, err := decompress(bytes.NewReader([]byte{0x1f, 0x8b, 0x00}))
+	assert.NotNil(t, err, "broken gzip header")
+}
+
+func TestTrimCompressionExt(t *testing.T) {
+	for _, tt := range []struct {
+		path     string
+		expected string
+	}{
+		{"data.csv.gz", "data.csv"},
+		{"data.csv.BZ2", "data.csv"},
+		{"dir/data.tsv.xz", "dir/data.tsv"},
+		{"data.xlsx.zst", "data.xlsx"},
+		{"data.csv", "data.csv"},
+		{"data.gz.csv", "data.gz.csv"},
+	} {
+		assert.Equal(t, tt.expected, trimCompressionExt(tt.path))
+	}
+}
+
+func TestCompressedReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cntblank")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	for _, tt := range []struct {
+		compression string
+		fname       string
+	}{
+		{"gzip", "data.csv.gz"},
+		{"bzip2", "data.csv.bz2"},
+		{"xz", "data.csv.xz"},
+		{"zstd", "data.csv.zst"},
+		{"gzip", "data.csv"}, // Magic bytes take precedence over extension.
+	} {
+		path := filepath.Join(dir, tt.fname)
+		require.Nil(t, ioutil.WriteFile(path, compressTestBytes(t, tt.compression), 0644))
+		reader, err := OpenFile(path, &csvhelper.FileDialect{Comma: ','})
+		require.Nil(t, err, tt.fname)
+		for _, expected := range [][]string{{"a", "b"}, {"1", "2"}} {
+			record, err := reader.Read()
+			assert.Nil(t, err, tt.fname)
+			assert.Equal(t, expected, record, tt.fname)
+		}
+		_, err = reader.Read()
+		assert.Equal(t, io.EOF, err, tt.fname)
+		reader.Close()
+	}
+}
+
+func TestCompressedExcelReader(t *testing.T) {
+	src, err := getTestfilePath("addrcode_jp.xlsx")
+	require.Nil(t, err)
+	b, err := ioutil.ReadFile(src)
+	require.Nil(t, err)
+	dir, err := ioutil.TempDir("", "cntblank")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	var z bytes.Buffer
+	w := gzip.NewWriter(&z)
+	w.Write(b)
+	w.Close()
+	path := filepath.Join(dir, "addrcode_jp.xlsx.gz")
+	require.Nil(t, ioutil.WriteFile(path, z.Bytes(), 0644))
+	reader, err := OpenFile(path, &csvhelper.FileDialect{SheetNumber: 2})
+	require.Nil(t, err)
+	record, err := reader.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"011002", "札幌市", "さっぽろし", "", ""}, record)
+}
+
+func TestCollectCompressedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cntblank")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	for _, fname := range []string{"a.csv.gz", "b.tsv.zst", "c.gz", "d.json.xz", "e.csv"} {
+		require.Nil(t, ioutil.WriteFile(filepath.Join(dir, fname), nil, 0644))
+	}
+	c := newFileCollector(false, []string{".csv", ".tsv"})
+	require.Nil(t, c.Collect(dir))
+	var names []string
+	for _, f := range c.files {
+		names = append(names, f.Name())
+	}
+	assert.Equal(t, []string{"a.csv.gz", "b.tsv.zst", "e.csv"}, names)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -28,12 +30,104 @@ var timeLayouts = []string{
 	"2006-01-02 15:4:5",
 }
 
-func parseDateTime(s string) (t time.Time, err error) {
-	for _, layout := range timeLayouts {
-		t, err = time.Parse(layout, s)
+// timeParser parses time values with layouts in the location,
+// which is used when the value does not have time zone.
+type timeParser struct {
+	layouts  []string
+	location *time.Location
+	wareki   bool // try dates in Japanese era after layouts
+}
+
+// parse parses s by trying each layout, and returns the layout which
+// matches s. The returned time is in the location of the parser.
+func (p *timeParser) parse(s string) (t time.Time, layout string, err error) {
+	for _, layout = range p.layouts {
+		t, err = time.ParseInLocation(layout, s, p.location)
 		if err == nil {
-			return
+			return t.In(p.location), layout, nil
+		}
+	}
+	if p.wareki {
+		if t, ok := parseWareki(s, p.location); ok {
+			return t, warekiLayout, nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no layout matches %q", s)
+	}
+	return t, "", err
+}
+
+// paddedElements maps elements of layout which accept one or two digits
+// to zero padded ones.
+var paddedElements = map[string]string{"1": "01", "2": "02", "4": "04", "5": "05"}
+
+// splitDigits splits s into runs of digits and runs of others.
+func splitDigits(s string) []string {
+	var runs []string
+	start := 0
+	for i := 1; i <= len(s); i++ {
+		if i == len(s) || isDigit(s[i]) != isDigit(s[i-1]) {
+			runs = append(runs, s[start:i])
+			start = i
+		}
+	}
+	return runs
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// observedLayout returns layout as observed in s, since unpadded elements
+// such as "1" of "2006/1/2" match both "1" and "01". An element is padded
+// when s has leading zero for it, and ambiguous elements such as "12"
+// follow the others. It reports ambiguous when none of elements shows
+// padding, where the padded layout is returned.
+func observedLayout(layout, s string) (observed string, ambiguous bool) {
+	elements, values := splitDigits(layout), splitDigits(s)
+	if len(elements) != len(values) {
+		return layout, false
+	}
+	padded, unpadded, paddable := false, false, false
+	for i, e := range elements {
+		if _, ok := paddedElements[e]; !ok || !isDigit(values[i][0]) {
+			continue
+		}
+		paddable = true
+		if len(values[i]) == 1 {
+			unpadded = true
+		} else if values[i][0] == '0' {
+			padded = true
+		}
+	}
+	if !paddable {
+		return layout, false
+	}
+	for i, e := range elements {
+		p, ok := paddedElements[e]
+		if !ok || !isDigit(values[i][0]) {
+			continue
+		}
+		if len(values[i]) == 2 && (values[i][0] == '0' || !unpadded) {
+			elements[i] = p
 		}
 	}
-	return
+	return strings.Join(elements, ""), !padded && !unpadded
+}
+
+// paddedLayout returns layout whose elements are all zero padded.
+func paddedLayout(layout string) string {
+	elements := splitDigits(layout)
+	for i, e := range elements {
+		if p, ok := paddedElements[e]; ok {
+			elements[i] = p
+		}
+	}
+	return strings.Join(elements, "")
+}
+
+// layoutHasClock reports whether layout has time of day.
+func layoutHasClock(layout string) bool {
+	return strings.Contains(layout, ":") || strings.Contains(layout, "15")
 }
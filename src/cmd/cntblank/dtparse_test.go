@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDateTime(t *testing.T) {
+	a := assert.New(t)
+	parser := NewProfileOption().timeParser()
+	for _, tc := range []struct {
+		s      string
+		layout string
+		want   string
+	}{
+		{"20150123", "20060102", "2015-01-23 00:00:00"},
+		{"2015/1/23", "2006/1/2", "2015-01-23 00:00:00"},
+		{"2015/01/23", "2006/1/2", "2015-01-23 00:00:00"},
+		{"2015/1/2 3:45", "2006/1/2 15:4", "2015-01-02 03:45:00"},
+		{"2015-01-02", "2006-01-02", "2015-01-02 00:00:00"},
+		{"2015-01-02 03:04:05", "2006-01-02 15:4:5", "2015-01-02 03:04:05"},
+		{"2015-01-02T03:04:05Z", "2006-01-02T15:04:05Z07:00", "2015-01-02 03:04:05"},
+		{"3:04PM", "3:04PM", "0000-01-01 15:04:00"},
+	} {
+		v, layout, err := parser.parse(tc.s)
+		a.Nil(err, "%q should be parsed", tc.s)
+		a.Equal(tc.layout, layout, "layout of %q", tc.s)
+		a.Equal(tc.want, v.Format("2006-01-02 15:04:05"))
+	}
+	_, layout, err := parser.parse("2015.01.23")
+	a.NotNil(err)
+	a.Equal("", layout)
+}
+
+func TestLayoutHasClock(t *testing.T) {
+	a := assert.New(t)
+	a.False(layoutHasClock("20060102"))
+	a.False(layoutHasClock("2006/1/2"))
+	a.True(layoutHasClock("2006/1/2 15:4"))
+	a.True(layoutHasClock("3:04PM"))
+	a.True(layoutHasClock("2006010215"))
+}
+
+func TestTimeParser(t *testing.T) {
+	a := assert.New(t)
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	p := &timeParser{
+		layouts:  []string{"2006-01-02T15:04:05.000-0700", "02/01/2006"},
+		location: jst,
+	}
+	v, layout, err := p.parse("2016-01-02T03:04:05.678+0000")
+	a.Nil(err)
+	a.Equal("2006-01-02T15:04:05.000-0700", layout)
+	a.Equal("2016-01-02 12:04:05 +0900", v.Format("2006-01-02 15:04:05 -0700"))
+	v, layout, err = p.parse("13/01/2016")
+	a.Nil(err)
+	a.Equal("02/01/2006", layout)
+	a.Equal("2016-01-13 00:00:00 +0900", v.Format("2006-01-02 15:04:05 -0700"))
+	_, _, err = p.parse("2016-01-13")
+	a.NotNil(err)
+}
+
+func TestObservedLayout(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		layout    string
+		s         string
+		observed  string
+		ambiguous bool
+	}{
+		{"2006/1/2", "2016/1/4", "2006/1/2", false},
+		{"2006/1/2", "2016/01/05", "2006/01/02", false},
+		{"2006/1/2", "2016/1/05", "2006/1/02", false},
+		{"2006/1/2", "2016/12/5", "2006/1/2", false},
+		{"2006/1/2", "2016/01/25", "2006/01/02", false},
+		{"2006/1/2", "2016/12/25", "2006/01/02", true},
+		{"2006/1/2 15:4", "2016/12/25 9:05", "2006/01/02 15:04", false},
+		{"2006/1/2 15:4", "2016/12/25 10:5", "2006/1/2 15:4", false},
+		{"2006-01-02", "2016-01-02", "2006-01-02", false},
+		{"20060102", "20160102", "20060102", false},
+		{time.RFC1123, "Mon, 02 Jan 2006 15:04:05 MST", time.RFC1123, false},
+	} {
+		observed, ambiguous := observedLayout(tc.layout, tc.s)
+		a.Equal(tc.observed, observed, "layout of %q", tc.s)
+		a.Equal(tc.ambiguous, ambiguous, "ambiguity of %q", tc.s)
+	}
+	a.Equal("2006/01/02 15:04", paddedLayout("2006/1/2 15:4"))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Units of numeric timestamps.
+const (
+	epochSeconds      = "s"
+	epochMilliseconds = "ms"
+	epochMicroseconds = "us"
+	excelSerial       = "excel"
+	excelCandidate    = "excel?"
+)
+
+// Plausible range of timestamps, which is from 1990 until 2100.
+var (
+	epochFrom = time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	epochTo   = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// excelEpoch is the origin of serial date in Excel, whose serial number
+// is 0. Excel wrongly treats 1900 as leap year, so it is not 1899-12-31.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// excelNameHints are parts of field names which suggest dates. Serial
+// dates of Excel are in range of ordinary integers such as prices, so the
+// name of field is required as evidence.
+var excelNameHints = []string{"date", "day", "time", "日", "年月"}
+
+// epochUnits are candidates of units with multiplier to nanoseconds.
+var epochUnits = []struct {
+	unit  string
+	scale float64
+}{
+	{epochSeconds, 1e9},
+	{epochMilliseconds, 1e6},
+	{epochMicroseconds, 1e3},
+}
+
+// detectEpoch guesses the unit of numeric timestamps in range of min and
+// max, or returns empty string when the range is not plausible.
+func detectEpoch(min, max float64) string {
+	for _, u := range epochUnits {
+		from := float64(epochFrom.UnixNano()) / u.scale
+		to := float64(epochTo.UnixNano()) / u.scale
+		if from <= min && max < to {
+			return u.unit
+		}
+	}
+	from := epochFrom.Sub(excelEpoch).Hours() / 24
+	to := epochTo.Sub(excelEpoch).Hours() / 24
+	if from <= min && max < to {
+		return excelSerial
+	}
+	return ""
+}
+
+// epochTime converts numeric timestamp in the unit to time.
+// Serial date of Excel is interpreted as wall clock in the location.
+func epochTime(v float64, unit string, location *time.Location) time.Time {
+	if unit == excelSerial {
+		days := math.Floor(v)
+		t := excelEpoch.AddDate(0, 0, int(days)).Add(time.Duration((v - days) * 24 * float64(time.Hour)))
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, location)
+	}
+	for _, u := range epochUnits {
+		if u.unit == unit {
+			return time.Unix(0, int64(v*u.scale)).In(location)
+		}
+	}
+	return time.Time{}
+}
+
+// isDateName reports whether name of field suggests dates.
+func isDateName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, hint := range excelNameHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeEpoch flags numeric field as timestamps when all numbers are
+// in plausible range, and fills MinTime and MaxTime of it.
+// Serial date of Excel without date-like name of field is reported only
+// as a candidate, and is not converted.
+func (f *ReportField) summarizeEpoch(location *time.Location) {
+	switch f.InferredType {
+	case IntegerType, DecimalType, CodeType:
+	default:
+		return
+	}
+	if f.TypeTime > 0 || f.Minimum == nil || f.Maximum == nil {
+		return
+	}
+	f.EpochUnit = detectEpoch(*f.Minimum, *f.Maximum)
+	if f.EpochUnit == "" {
+		return
+	}
+	if f.EpochUnit == excelSerial && !isDateName(f.Name) {
+		f.EpochUnit = excelCandidate
+		return
+	}
+	if f.InferredType == CodeType {
+		// Zero-padded digits whose range looks like an epoch are timestamps.
+		f.InferredType = IntegerType
+	}
+	minTime := epochTime(*f.Minimum, f.EpochUnit, location)
+	maxTime := epochTime(*f.Maximum, f.EpochUnit, location)
+	f.MinTime = &minTime
+	f.MaxTime = &maxTime
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectEpoch(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		min, max float64
+		unit     string
+	}{
+		{1451606400, 1483228799, epochSeconds},
+		{1451606400.5, 1451606401.5, epochSeconds},
+		{1451606400000, 1483228799999, epochMilliseconds},
+		{1451606400000000, 1483228799999999, epochMicroseconds},
+		{42370, 42735, excelSerial},
+		{42370.25, 42370.75, excelSerial},
+		{0, 1483228799, ""},
+		{1451606400, 1483228799000, ""},
+		{1, 100, ""},
+		{20160101, 20161231, ""},
+		{5000000000, 6000000000, ""},
+	} {
+		a.Equal(tc.unit, detectEpoch(tc.min, tc.max), "%v-%v", tc.min, tc.max)
+	}
+}
+
+func TestEpochTime(t *testing.T) {
+	a := assert.New(t)
+	layout := "2006-01-02 15:04:05"
+	a.Equal("2016-01-01 00:00:00", epochTime(1451606400, epochSeconds, time.UTC).Format(layout))
+	a.Equal("2016-01-01 00:00:00", epochTime(1451606400000, epochMilliseconds, time.UTC).Format(layout))
+	a.Equal("2016-01-01 00:00:00", epochTime(1451606400000000, epochMicroseconds, time.UTC).Format(layout))
+	a.Equal("2016-01-01 00:00:00", epochTime(42370, excelSerial, time.UTC).Format(layout))
+	a.Equal("2016-01-01 18:00:00", epochTime(42370.75, excelSerial, time.UTC).Format(layout))
+	jst := time.FixedZone("JST", 9*60*60)
+	a.Equal("2016-01-01 09:00:00", epochTime(1451606400, epochSeconds, jst).Format(layout))
+	a.Equal("2016-01-01 00:00:00", epochTime(42370, excelSerial, jst).Format(layout))
+}
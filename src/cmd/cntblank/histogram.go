@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// HistogramBin is a bin of equi-width histogram, which counts values from
+// From (inclusive) to To (exclusive, but inclusive for the last bin), so
+// that a value on the boundary belongs to the upper bin.
+// FromTime and ToTime are set instead for time values.
+type HistogramBin struct {
+	From     *float64   `json:"from,omitempty"`
+	To       *float64   `json:"to,omitempty"`
+	FromTime *time.Time `json:"fromTime,omitempty"`
+	ToTime   *time.Time `json:"toTime,omitempty"`
+	Count    int        `json:"count"`
+}
+
+func (b HistogramBin) String() string {
+	if b.FromTime != nil {
+		return fmt.Sprintf("%s-%s", b.FromTime.Format("2006-01-02 15:04:05"), b.ToTime.Format("2006-01-02 15:04:05"))
+	}
+	return fmt.Sprintf("%.4f-%.4f", *b.From, *b.To)
+}
+
+// histogram estimates counts of values in equi-width bins between minimum
+// and maximum by counts of values below each edge.
+func (d *tdigest) histogram(bins int) (edges []float64, counts []int) {
+	if bins <= 0 || d.count == 0 {
+		return nil, nil
+	}
+	if d.min == d.max {
+		return []float64{d.min, d.max}, []int{int(d.count)}
+	}
+	edges = make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = d.min + (d.max-d.min)*float64(i)/float64(bins)
+	}
+	edges[bins] = d.max
+	counts = make([]int, bins)
+	// Round cumulative counts rather than each bin, so that sum of counts
+	// equals to the number of values.
+	prev := 0
+	for i := 1; i <= bins; i++ {
+		cum := int(math.Floor(d.countBelow(edges[i]) + 0.5))
+		if i == bins {
+			cum = int(d.count)
+		}
+		counts[i-1] = cum - prev
+		prev = cum
+	}
+	return edges, counts
+}
+
+// countBelow estimates the number of values less than x. Centroid of one
+// value is the value itself, and values of larger centroid are assumed to
+// spread evenly between midpoints to its neighbors.
+func (d *tdigest) countBelow(x float64) float64 {
+	d.compress()
+	cum := 0.0
+	for i, c := range d.centroids {
+		if c.weight == 1 {
+			if c.mean < x {
+				cum++
+			}
+			continue
+		}
+		lo, hi := d.min, d.max
+		if i > 0 {
+			lo = (d.centroids[i-1].mean + c.mean) / 2
+		}
+		if i < len(d.centroids)-1 {
+			hi = (c.mean + d.centroids[i+1].mean) / 2
+		}
+		switch {
+		case x > hi:
+			cum += c.weight
+		case x > lo:
+			cum += c.weight * (x - lo) / (hi - lo)
+		}
+	}
+	return cum
+}
+
+// summarizeHistogram sets histogram of numbers or times of the field.
+func (f *ReportField) summarizeHistogram(bins int, location *time.Location) {
+	digest := f.numDigest
+	if f.useTime() {
+		digest = f.timeDigest
+	}
+	if digest == nil {
+		return
+	}
+	edges, counts := digest.histogram(bins)
+	if counts == nil {
+		return
+	}
+	f.Histogram = make([]HistogramBin, len(counts))
+	for i, count := range counts {
+		b := &f.Histogram[i]
+		b.Count = count
+		if digest == f.timeDigest {
+			from, to := digestTime(edges[i], location), digestTime(edges[i+1], location)
+			b.FromTime, b.ToTime = &from, &to
+		} else {
+			from, to := edges[i], edges[i+1]
+			b.From, b.To = &from, &to
+		}
+	}
+}
+
+// formatHistogram returns bins with their counts as one string.
+func (r *ReportField) formatHistogram() string {
+	bins := make([]string, 0, len(r.Histogram))
+	for _, b := range r.Histogram {
+		bins = append(bins, fmt.Sprintf("%s:%d", b, b.Count))
+	}
+	return strings.Join(bins, " ")
+}
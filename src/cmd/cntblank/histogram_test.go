@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTDigestHistogram(t *testing.T) {
+	a := assert.New(t)
+	d := newTDigest(digestCompression)
+	edges, counts := d.histogram(4)
+	a.Nil(edges)
+	a.Nil(counts)
+	for i := 0; i < 100; i++ {
+		d.add(float64(i))
+	}
+	edges, counts = d.histogram(4)
+	a.Equal([]float64{0, 24.75, 49.5, 74.25, 99}, edges)
+	a.Equal(4, len(counts))
+	sum := 0
+	for _, c := range counts {
+		a.InDelta(25, c, 1)
+		sum += c
+	}
+	a.Equal(100, sum)
+	// Values on edges belong to upper bins, except the maximum.
+	d = newTDigest(digestCompression)
+	for _, x := range []float64{0, 1, 2, 3, 4} {
+		d.add(x)
+	}
+	edges, counts = d.histogram(2)
+	a.Equal([]float64{0, 2, 4}, edges)
+	a.Equal([]int{2, 3}, counts)
+	d = newTDigest(digestCompression)
+	d.add(5)
+	d.add(5)
+	edges, counts = d.histogram(4)
+	a.Equal([]float64{5, 5}, edges)
+	a.Equal([]int{2}, counts)
+}
+
+func TestReportHistogram(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.HistogramBins = 2
+	report := newReport(File{}, option)
+	for _, s := range [][]string{
+		{"1", "2016-01-01"},
+		{"2", "2016-01-02"},
+		{"3", "2016-01-03"},
+		{"10", "2016-01-05"},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(2, len(f.Histogram))
+	a.Equal(1.0, *f.Histogram[0].From)
+	a.Equal(5.5, *f.Histogram[0].To)
+	a.Equal(3, f.Histogram[0].Count)
+	a.Equal(1, f.Histogram[1].Count)
+	a.Nil(f.Histogram[0].FromTime)
+	a.Equal("1.0000-5.5000:3 5.5000-10.0000:1", f.formatHistogram())
+	f = report.Fields[1]
+	a.Equal(2, len(f.Histogram))
+	a.Nil(f.Histogram[0].From)
+	a.Equal(time.Date(2016, 1, 3, 0, 0, 0, 0, time.UTC), *f.Histogram[0].ToTime)
+	// The boundary value "2016-01-03" belongs to the upper bin.
+	a.Equal(2, f.Histogram[0].Count)
+	a.Equal(2, f.Histogram[1].Count)
+
+	option.HistogramBins = 0
+	report = newReport(File{}, option)
+	report.parseRecord([]string{"1"})
+	report.summarize()
+	a.Nil(report.Fields[0].Histogram)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// hllPrecision is the number of bits to select a register of HyperLogLog,
+// whose standard error is 1.04/sqrt(2^14), which is about 0.8%.
+const hllPrecision = 14
+
+// hyperLogLog is a sketch to estimate cardinality of a stream of values.
+// See "HyperLogLog: the analysis of a near-optimal cardinality estimation
+// algorithm" by Flajolet et al.
+type hyperLogLog struct {
+	registers []uint8
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{
+		registers: make([]uint8, 1<<hllPrecision),
+	}
+}
+
+func (h *hyperLogLog) add(hash uint64) {
+	index := hash >> (64 - hllPrecision)
+	rest := hash<<hllPrecision | 1<<(hllPrecision-1)
+	rank := uint8(1)
+	for rest&(1<<63) == 0 {
+		rank++
+		rest <<= 1
+	}
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+func (h *hyperLogLog) estimate() float64 {
+	m := float64(len(h.registers))
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Use linear counting on small range.
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return estimate
+}
+
+// stdError returns relative standard error of the estimate.
+func (h *hyperLogLog) stdError() float64 {
+	return 1.04 / math.Sqrt(float64(len(h.registers)))
+}
+
+// distinctCounter counts distinct values exactly while the number of
+// distinct values is under threshold, and then switches to HyperLogLog.
+type distinctCounter struct {
+	exact  map[uint64]struct{}
+	sketch *hyperLogLog
+}
+
+func (c *distinctCounter) add(s string, threshold int) {
+	hash := hashString(s)
+	if c.sketch != nil {
+		c.sketch.add(hash)
+		return
+	}
+	if c.exact == nil {
+		c.exact = make(map[uint64]struct{})
+	}
+	c.exact[hash] = struct{}{}
+	if len(c.exact) > threshold {
+		c.sketch = newHyperLogLog()
+		for h := range c.exact {
+			c.sketch.add(h)
+		}
+		c.exact = nil
+	}
+}
+
+// count returns the number of distinct values and whether it is exact.
+func (c *distinctCounter) count() (int, bool) {
+	if c.sketch != nil {
+		return int(c.sketch.estimate() + 0.5), false
+	}
+	return len(c.exact), true
+}
+
+// hashString returns 64-bit hash of s, whose bits are well mixed.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	// Finalizer of MurmurHash3 to avalanche bits.
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	a := assert.New(t)
+	h := newHyperLogLog()
+	a.Equal(0.0, h.estimate())
+	for _, n := range []int{100, 10000, 200000} {
+		h = newHyperLogLog()
+		for i := 0; i < n; i++ {
+			h.add(hashString(fmt.Sprint(i)))
+			h.add(hashString(fmt.Sprint(i))) // duplicates do not change estimate
+		}
+		err := math.Abs(h.estimate()-float64(n)) / float64(n)
+		a.True(err < 3*h.stdError(), "estimate of %d has too large error %.4f", n, err)
+	}
+}
+
+func TestDistinctCounter(t *testing.T) {
+	a := assert.New(t)
+	c := distinctCounter{}
+	n, exact := c.count()
+	a.Equal(0, n)
+	a.True(exact)
+	for _, s := range []string{"a", "b", "a", "c"} {
+		c.add(s, 3)
+	}
+	n, exact = c.count()
+	a.Equal(3, n)
+	a.True(exact)
+	c.add("d", 3)
+	n, exact = c.count()
+	a.Equal(4, n)
+	a.False(exact, "counter should switch to sketch over threshold")
+	a.Nil(c.exact)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"strings"
+)
+
+// DataType represents inferred data type of a field.
+type DataType int
+
+const (
+	// UnknownType is nil value of DataType, which is not inferred yet
+	UnknownType DataType = iota
+	// EmptyType is a type of field whose cells are all blank
+	EmptyType
+	// IntegerType is integer number
+	IntegerType
+	// DecimalType is real number
+	DecimalType
+	// BooleanType is boolean value
+	BooleanType
+	// DateType is date without time of day
+	DateType
+	// DateTimeType is date with time of day
+	DateTimeType
+	// CodeType is digits which should be kept as string such as zip code
+	CodeType
+	// StringType is arbitrary string
+	StringType
+)
+
+func (t DataType) String() string {
+	switch t {
+	case UnknownType:
+		return "unknown"
+	case EmptyType:
+		return "empty"
+	case IntegerType:
+		return "integer"
+	case DecimalType:
+		return "decimal"
+	case BooleanType:
+		return "boolean"
+	case DateType:
+		return "date"
+	case DateTimeType:
+		return "datetime"
+	case CodeType:
+		return "code"
+	case StringType:
+		return "string"
+	default:
+		return "undefined"
+	}
+}
+
+// MarshalText encodes DataType as its name in JSON.
+func (t DataType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// isDigits reports whether s consists of ASCII digits only.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// addCode counts numbers which look like code, which have leading zeros
+// such as "007" or plus sign such as "+81", and tracks length of digits.
+func (f *ReportField) addCode(s string) {
+	if !isDigits(strings.TrimPrefix(s, "+")) {
+		return
+	}
+	if s[0] == '+' {
+		f.TypeCode++
+		return
+	}
+	if len(s) > 1 && s[0] == '0' {
+		f.TypeCode++
+	}
+	if f.typeDigits == 0 {
+		f.digitLength = len(s)
+	} else if f.digitLength != len(s) {
+		f.digitLength = -1
+	}
+	f.typeDigits++
+}
+
+// inferType resolves one data type of the field from counters of each
+// type, and sets the ratio of matched cells to non-blank cells.
+// Types are examined in order of code, integer, decimal, date/time and
+// boolean, and the first one whose ratio reaches threshold is taken.
+// Digits of fixed length are code when some of them have leading zeros,
+// unless they are dates.
+// Threshold of zero, which is left unset, falls back to the default.
+func (f *ReportField) inferType(records int, threshold float64) {
+	if threshold <= 0 {
+		threshold = defaultProfileOption.TypeThreshold
+	}
+	filled := records - f.Blank
+	if filled <= 0 {
+		f.InferredType = EmptyType
+		f.TypeConfidence = 0
+		return
+	}
+	timeType := DateTimeType
+	if f.typeDate == f.TypeTime {
+		timeType = DateType
+	}
+	code := f.TypeCode
+	if f.digitLength > 1 && f.TypeCode > 0 &&
+		float64(f.TypeTime)/float64(filled) < threshold && f.typeDigits > code {
+		code = f.typeDigits
+	}
+	maxRatio := 0.0
+	for _, c := range []struct {
+		dataType DataType
+		count    int
+	}{
+		{CodeType, code},
+		{IntegerType, f.TypeInt},
+		{DecimalType, f.TypeFloat},
+		{timeType, f.TypeTime},
+		{BooleanType, f.TypeBool},
+	} {
+		ratio := float64(c.count) / float64(filled)
+		if ratio >= threshold {
+			f.InferredType = c.dataType
+			f.TypeConfidence = ratio
+			return
+		}
+		if ratio > maxRatio {
+			maxRatio = ratio
+		}
+	}
+	f.InferredType = StringType
+	f.TypeConfidence = 1 - maxRatio
+}
+
+// dropNumbers clears numeric statistics of code field, so that every
+// writer agrees that numeric values of code are meaningless, such as "1"
+// for "01".
+func (f *ReportField) dropNumbers() {
+	f.Minimum, f.Maximum = nil, nil
+	f.Mean, f.Variance, f.StdDev, f.Sum = nil, nil, nil, nil
+	f.Median = nil
+	f.Quantiles = nil
+	f.Histogram = nil
+	f.OutliersIQR, f.OutliersZScore = 0, 0
+	f.Outliers = nil
+}
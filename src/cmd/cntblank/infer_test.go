@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataTypeString(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		dataType DataType
+		want     string
+	}{
+		{UnknownType, "unknown"},
+		{EmptyType, "empty"},
+		{IntegerType, "integer"},
+		{DecimalType, "decimal"},
+		{BooleanType, "boolean"},
+		{DateType, "date"},
+		{DateTimeType, "datetime"},
+		{CodeType, "code"},
+		{StringType, "string"},
+	} {
+		a.Equal(tc.want, tc.dataType.String())
+	}
+	b, err := json.Marshal(struct {
+		T DataType `json:"t"`
+	}{DateTimeType})
+	a.Nil(err)
+	a.Equal(`{"t":"datetime"}`, string(b))
+}
+
+func TestInferTypeDefaultThreshold(t *testing.T) {
+	a := assert.New(t)
+	report := newReport(File{}, &ProfileOption{})
+	for _, s := range []string{"a", "b", "c", "1"} {
+		report.parseRecord([]string{s})
+	}
+	report.summarize()
+	a.Equal(StringType, report.Fields[0].InferredType, "zero threshold should be the default")
+}
+
+func TestInferType(t *testing.T) {
+	a := assert.New(t)
+	for i, tc := range []struct {
+		records    []string
+		threshold  float64
+		dataType   DataType
+		confidence float64
+	}{
+		{[]string{"", " "}, 0.9, EmptyType, 0},
+		{[]string{"1", "-2", "", "30"}, 0.9, IntegerType, 1},
+		{[]string{"1", "2.5", "3"}, 0.9, DecimalType, 1},
+		{[]string{"1", "2", "x"}, 0.9, StringType, 1 - 2.0/3},
+		{[]string{"1", "2", "x"}, 0.6, IntegerType, 2.0 / 3},
+		{[]string{"true", "F", "False"}, 0.9, BooleanType, 1},
+		{[]string{"0", "1", "1"}, 0.9, IntegerType, 1},
+		{[]string{"2016-01-02", "2016/1/3"}, 0.9, DateType, 1},
+		{[]string{"2016-01-02", "2016/1/3 12:34"}, 0.9, DateTimeType, 1},
+		{[]string{"abc", "北海道"}, 0.9, StringType, 1},
+		{[]string{"007", "010", "+81"}, 0.9, CodeType, 1},
+		{[]string{"01", "13", "47"}, 0.9, CodeType, 1},
+		{[]string{"1", "13", "47"}, 0.9, IntegerType, 1},
+		{[]string{"10", "13", "47"}, 0.9, IntegerType, 1},
+		{[]string{"1000001", "0600000", ""}, 0.9, CodeType, 1},
+		{[]string{"131016", "011002"}, 0.9, CodeType, 1},
+		{[]string{"12345", "23456"}, 0.9, IntegerType, 1},
+		{[]string{"1234", "2345"}, 0.9, IntegerType, 1},
+		{[]string{"01", "1.5", "2"}, 0.9, DecimalType, 1},
+		{[]string{"20160102", "20160103"}, 0.9, IntegerType, 1},
+		{[]string{"0", "1", "2"}, 0.9, IntegerType, 1},
+	} {
+		option := NewProfileOption()
+		option.TypeThreshold = tc.threshold
+		report := newReport(File{}, option)
+		for _, s := range tc.records {
+			report.parseRecord([]string{s})
+		}
+		report.summarize()
+		f := report.Fields[0]
+		a.Equal(tc.dataType, f.InferredType, "#%d inferred type", i+1)
+		a.InDelta(tc.confidence, f.TypeConfidence, 1e-9, "#%d type confidence", i+1)
+	}
+}
+
+func TestReportFieldAddCode(t *testing.T) {
+	a := assert.New(t)
+	f := new(ReportField)
+	for _, s := range []string{"0", "12", "007", "+81", "-1", "1.0", "x"} {
+		f.addCode(s)
+	}
+	a.Equal(2, f.TypeCode)
+	a.Equal(3, f.typeDigits)
+	a.Equal(-1, f.digitLength)
+	f = new(ReportField)
+	for _, s := range []string{"01", "47"} {
+		f.addCode(s)
+	}
+	a.Equal(1, f.TypeCode)
+	a.Equal(2, f.digitLength)
+}
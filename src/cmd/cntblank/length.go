@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// LengthBucket is a bin of length histogram, which counts non-blank cells
+// whose length in runes is from From to To inclusive.
+// To is 0 for the last bucket which has no upper bound.
+type LengthBucket struct {
+	From  int `json:"from"`
+	To    int `json:"to,omitempty"`
+	Count int `json:"count"`
+}
+
+func (b LengthBucket) String() string {
+	if b.To == 0 {
+		return fmt.Sprintf("%d-", b.From)
+	}
+	return fmt.Sprintf("%d-%d", b.From, b.To)
+}
+
+// sjisLength returns the number of bytes of s in Shift_JIS, where ASCII
+// and half-width katakana are single byte and others are double bytes.
+func sjisLength(s string) int {
+	n := 0
+	for _, r := range s {
+		if r < utf8.RuneSelf || (r >= '｡' && r <= 'ﾟ') {
+			n++
+		} else {
+			n += 2
+		}
+	}
+	return n
+}
+
+// addLength counts length of non-blank cell by upper bounds of buckets.
+func (f *ReportField) addLength(s string, length int, bounds []int) {
+	f.totalLength += length
+	if n := len(s); n > f.MaxByteLength {
+		f.MaxByteLength = n
+	}
+	if n := sjisLength(s); n > f.MaxSJISByteLength {
+		f.MaxSJISByteLength = n
+	}
+	if f.lengthCounts == nil {
+		f.lengthCounts = make([]int, len(bounds)+1)
+	}
+	i := 0
+	for i < len(bounds) && length > bounds[i] {
+		i++
+	}
+	f.lengthCounts[i]++
+}
+
+// summarizeLength sets mean length and histogram of lengths.
+func (f *ReportField) summarizeLength(records int, bounds []int) {
+	filled := records - f.Blank
+	if filled <= 0 || f.lengthCounts == nil {
+		return
+	}
+	f.MeanLength = float64(f.totalLength) / float64(filled)
+	f.LengthHistogram = make([]LengthBucket, 0, len(f.lengthCounts))
+	from := 1
+	for i, count := range f.lengthCounts {
+		b := LengthBucket{From: from, Count: count}
+		if i < len(bounds) {
+			b.To = bounds[i]
+			from = bounds[i] + 1
+		}
+		f.LengthHistogram = append(f.LengthHistogram, b)
+	}
+}
+
+// formatLengthHistogram returns buckets with their counts as one string.
+func (r *ReportField) formatLengthHistogram() string {
+	buckets := make([]string, 0, len(r.LengthHistogram))
+	for _, b := range r.LengthHistogram {
+		buckets = append(buckets, fmt.Sprintf("%s:%d", b, b.Count))
+	}
+	return strings.Join(buckets, " ")
+}
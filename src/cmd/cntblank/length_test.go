@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSJISLength(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(0, sjisLength(""))
+	a.Equal(3, sjisLength("abc"))
+	a.Equal(6, sjisLength("北海道"))
+	a.Equal(3, sjisLength("ｱｲｳ"))
+	a.Equal(6, sjisLength("A東京ｶ"))
+}
+
+func TestReportLength(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.LengthBuckets = []int{2, 4}
+	report := newReport(File{}, option)
+	for _, s := range []string{"a", "abc", "", "北海道", "abcdefgh", "ab"} {
+		report.parseRecord([]string{s})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.InDelta(17.0/5, f.MeanLength, 1e-9)
+	a.Equal(8, f.MaxLength)
+	a.Equal(9, f.MaxByteLength)
+	a.Equal(8, f.MaxSJISByteLength)
+	a.Equal([]LengthBucket{
+		{From: 1, To: 2, Count: 2},
+		{From: 3, To: 4, Count: 2},
+		{From: 5, Count: 1},
+	}, f.LengthHistogram)
+	a.Equal("1-2:2 3-4:2 5-:1", f.formatLengthHistogram())
+}
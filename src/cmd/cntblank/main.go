@@ -1,9 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
@@ -15,18 +17,39 @@ import (
 var (
 	cli             = kingpin.New("cntblank", "Count blank cells on text-based tabular data.")
 	cliVerbose      = cli.Flag("verbose", "Set verbose mode on.").Short('v').Bool()
-	cliInEncoding   = cli.Flag("input-encoding", "Input encoding.").Short('e').Default("utf8").String()
+	cliInEncoding   = cli.Flag("input-encoding", "Input encoding, or \"auto\" to detect it.").Short('e').Default("utf8").String()
 	cliOutEncoding  = cli.Flag("output-encoding", "Output encoding.").Short('E').Default("utf8").String()
-	cliInDelimiter  = cli.Flag("input-delimiter", "Input field delimiter.").Default("\t").String()
+	cliInDelimiter  = cli.Flag("input-delimiter", "Input field delimiter, which is sniffed if empty.").String()
 	cliOutDelimiter = cli.Flag("output-delimiter", "Output field delmiter.").Default("\t").String()
 	cliNoHeader     = cli.Flag("without-header", "Tabular does not have header line.").Bool()
 	cliOutNoHeader  = cli.Flag("output-without-header", "Output report does not have header line.").Bool()
 	cliStrict       = cli.Flag("strict", "Check column size strictly.").Bool()
 	cliSheet        = cli.Flag("sheet", "Excel sheet number which starts with 1.").Int()
+	cliNullValues   = cli.Flag("null-values", "Token treated as blank such as NULL, which is repeatable.").Strings()
+	cliNullNoCase   = cli.Flag("null-ignore-case", "Match null tokens in case-insensitive.").Bool()
 	cliRecursive    = cli.Flag("recursive", "Traverse directory recursively.").Short('r').Bool()
 	cliOutMeta      = cli.Flag("output-meta", "Put meta information.").Bool()
+	cliOutBOM       = cli.Flag("output-bom", "Write byte order mark on CSV output for Excel.").Bool()
 	cliOutput       = cli.Flag("output", "Output file.").Short('o').String()
 	cliOutFormat    = cli.Flag("output-format", "Output format.").String()
+	cliPercentiles  = cli.Flag("percentile", "Percentile to estimate in addition to median, which is repeatable.").Default("1", "5", "25", "75", "95", "99").Float64List()
+	cliDistinct     = cli.Flag("distinct-threshold", "Count distinct values exactly up to this number, and estimate beyond it.").Default("10000").Int()
+	cliTopValues    = cli.Flag("top-values", "Number of most frequent values to report, or 0 to disable.").Default("5").Int()
+	cliLengthBucket = cli.Flag("length-bucket", "Upper bound of bucket of length histogram, which is repeatable.").Default("8", "16", "32", "64", "128", "256").Ints()
+	cliBins         = cli.Flag("histogram-bins", "Number of bins of histogram of numbers and times, or 0 to disable.").Default("10").Int()
+	cliOutlierIQR   = cli.Flag("outlier-iqr", "Outlier is beyond this times of interquartile range from quartiles.").Default("1.5").Float64()
+	cliOutlierZ     = cli.Flag("outlier-zscore", "Outlier is beyond this times of standard deviation from mean.").Default("3").Float64()
+	cliOutlierEx    = cli.Flag("outlier-examples", "Number of outliers to report as examples.").Default("3").Int()
+	cliTopPatterns  = cli.Flag("top-patterns", "Number of most common patterns of values to report, or 0 to disable.").Default("5").Int()
+	cliThreshold    = cli.Flag("type-threshold", "Ratio of non-blank cells to infer data type of field.").Default("0.95").Float64()
+	cliTimeLayouts  = cli.Flag("time-layout", "Layout of time values in Go reference time, which is repeatable.").Strings()
+	cliTimeOnly     = cli.Flag("time-layout-only", "Use only layouts given by --time-layout.").Bool()
+	cliEpoch        = cli.Flag("detect-epoch", "Detect numeric timestamps such as Unix epoch and Excel serial date.").Bool()
+	cliNumLocale    = cli.Flag("number-locale", "Locale to recognize formatted numbers such as ja-JP, en-US, de-DE and fr-FR.").String()
+	cliPII          = cli.Flag("detect-pii", "Detect personal data such as email, phone number and credit card number.").Bool()
+	cliPIIThreshold = cli.Flag("pii-threshold", "Ratio of non-blank cells of personal data to flag field as PII.").Default("0.5").Float64()
+	cliTextAll      = cli.Flag("text-all-columns", "Write all columns in text output instead of compact ones.").Bool()
+	cliTimeZone     = cli.Flag("time-zone", "Time zone of time values without offset such as Asia/Tokyo.").Default("UTC").String()
 	cliTabularFiles = cli.Arg("tabfile", "Tabular data files.").Strings()
 )
 
@@ -61,13 +84,25 @@ func main() {
 	} else {
 		output = os.Stdout
 	}
-	inDialect, outDialect := populateIODialect()
+	inDialect, outDialect, err := populateIODialect()
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
 	// Run main application logic.
-	app, err := newApplication(*cliRecursive, output, format, outDialect)
+	option, err := populateProfileOption()
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	app, err := newApplication(*cliRecursive, output, format, outDialect, option)
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
+	if w, ok := app.writer.(*ReportTextWriter); ok {
+		w.allColumns = *cliTextAll
+	}
 	files := *cliTabularFiles
 	err = app.Run(files, inDialect)
 	if err != nil {
@@ -75,19 +110,56 @@ func main() {
 	}
 }
 
-func populateIODialect() (inDialect *csvhelper.FileDialect, outDialect *csvhelper.FileDialect) {
-	inDialect, err := csvhelper.NewFileDialect(*cliInDelimiter, *cliInEncoding, !*cliNoHeader)
+func populateIODialect() (inDialect *csvhelper.FileDialect, outDialect *csvhelper.FileDialect, err error) {
+	inDialect, err = csvhelper.NewFileDialect(*cliInDelimiter, *cliInEncoding, !*cliNoHeader)
 	if err != nil {
-		// TODO: report error.
+		return nil, nil, fmt.Errorf("input dialect: %v", err)
+	}
+	if *cliInDelimiter == "" {
+		// Tab is the fallback when delimiter cannot be sniffed.
+		inDialect.Comma = '\t'
+		inDialect.Sniff = true
 	}
 	inDialect.SheetNumber = *cliSheet
+	inDialect.NullValues = *cliNullValues
+	inDialect.NullIgnoreCase = *cliNullNoCase
 	if *cliStrict {
 		inDialect.FieldsPerRecord = 0
 	}
 	outDialect, err = csvhelper.NewFileDialect(*cliOutDelimiter, *cliOutEncoding, !*cliOutNoHeader)
 	if err != nil {
-		// TODO: report error.
+		return nil, nil, fmt.Errorf("output dialect: %v", err)
+	}
+	if outDialect.Encoding == csvhelper.EncodingAuto {
+		return nil, nil, fmt.Errorf("output dialect: encoding %q is available only for input", csvhelper.EncodingAuto)
 	}
 	outDialect.HasMetadata = *cliOutMeta
-	return
+	outDialect.WriteBOM = *cliOutBOM
+	return inDialect, outDialect, nil
+}
+
+func populateProfileOption() (*ProfileOption, error) {
+	option := NewProfileOption()
+	option.Percentiles = *cliPercentiles
+	option.DistinctThreshold = *cliDistinct
+	option.TopValues = *cliTopValues
+	option.TopPatterns = *cliTopPatterns
+	option.LengthBuckets = *cliLengthBucket
+	option.HistogramBins = *cliBins
+	option.OutlierIQR = *cliOutlierIQR
+	option.OutlierZScore = *cliOutlierZ
+	option.OutlierExamples = *cliOutlierEx
+	option.TypeThreshold = *cliThreshold
+	option.TimeLayouts = *cliTimeLayouts
+	option.TimeLayoutOnly = *cliTimeOnly
+	option.DetectEpoch = *cliEpoch
+	option.NumberLocale = *cliNumLocale
+	option.DetectPII = *cliPII
+	option.PIIThreshold = *cliPIIThreshold
+	location, err := time.LoadLocation(*cliTimeZone)
+	if err != nil {
+		return nil, err
+	}
+	option.Location = location
+	return option, nil
 }
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+// numberRecognizer parses formatted numbers which strconv rejects, such
+// as "1,234", "¥1,000", "12.5%" and "(300)".
+// The unit is currency symbol or percent sign written with the number,
+// and integral reports whether the number is written without decimals.
+type numberRecognizer interface {
+	parse(s string) (v float64, unit string, integral bool, ok bool)
+}
+
+// numberLocale is conventions to format numbers in a locale.
+type numberLocale struct {
+	thousands  []string // thousands separators
+	decimal    string   // decimal separator
+	currencies []string // currency symbols written before or after numbers
+	negatives  []string // negative signs in addition to "-"
+}
+
+// numberLocales are presets of number recognizer by locale name.
+var numberLocales = map[string]numberRecognizer{
+	"ja-JP": &numberLocale{
+		thousands:  []string{","},
+		decimal:    ".",
+		currencies: []string{"¥", "円", "JPY"},
+		negatives:  []string{"▲", "△", "−"},
+	},
+	"en-US": &numberLocale{
+		thousands:  []string{","},
+		decimal:    ".",
+		currencies: []string{"$", "USD"},
+		negatives:  []string{"−"},
+	},
+	"de-DE": &numberLocale{
+		thousands:  []string{".", " "},
+		decimal:    ",",
+		currencies: []string{"€", "EUR"},
+		negatives:  []string{"−"},
+	},
+	"fr-FR": &numberLocale{
+		thousands:  []string{" ", "\u00a0", "\u202f"},
+		decimal:    ",",
+		currencies: []string{"€", "EUR"},
+		negatives:  []string{"−"},
+	},
+}
+
+// lookupNumberRecognizer returns the recognizer of the locale.
+func lookupNumberRecognizer(locale string) (numberRecognizer, error) {
+	if r, ok := numberLocales[locale]; ok {
+		return r, nil
+	}
+	names := make([]string, 0, len(numberLocales))
+	for name := range numberLocales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("number locale should be one of %s, but %q", strings.Join(names, ", "), locale)
+}
+
+func (l *numberLocale) parse(s string) (v float64, unit string, integral bool, ok bool) {
+	// Full-width digits and symbols such as "１２３" and "￥" are narrowed.
+	narrow := strings.TrimSpace(width.Narrow.String(s))
+	formatted := narrow != s
+	s = narrow
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		// Accounting style of negative number.
+		negative, formatted = true, true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	sign := func() {
+		if strings.HasPrefix(s, "+") {
+			s = s[1:]
+			return
+		}
+		if strings.HasPrefix(s, "-") {
+			negative = !negative
+			s = s[1:]
+			return
+		}
+		for _, n := range l.negatives {
+			if strings.HasPrefix(s, n) {
+				negative, formatted = !negative, true
+				s = s[len(n):]
+				return
+			}
+		}
+	}
+	sign()
+	for _, c := range l.currencies {
+		if strings.HasPrefix(s, c) {
+			unit = c
+			s = strings.TrimSpace(s[len(c):])
+			sign()
+			break
+		}
+	}
+	if unit == "" {
+		for _, c := range append([]string{"%"}, l.currencies...) {
+			if strings.HasSuffix(s, c) {
+				unit = c
+				s = strings.TrimSpace(s[:len(s)-len(c)])
+				break
+			}
+		}
+	}
+	number, integral, ok := l.normalize(s)
+	if !ok || (unit == "" && !formatted && number == s) {
+		// Plain numbers are parsed by strconv.
+		return 0, "", false, false
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, "", false, false
+	}
+	if negative {
+		v = -v
+	}
+	return v, unit, integral, true
+}
+
+// normalize converts number in the locale to the form of strconv, where
+// thousands separators should separate every three digits.
+func (l *numberLocale) normalize(s string) (number string, integral bool, ok bool) {
+	if s == "" {
+		return "", false, false
+	}
+	integer, fraction := s, ""
+	if i := strings.LastIndex(s, l.decimal); i >= 0 {
+		integer, fraction = s[:i], s[i+len(l.decimal):]
+		if !isDigits(fraction) {
+			return "", false, false
+		}
+	}
+	if integer == "" {
+		integer = "0"
+	}
+	groups := []string{integer}
+	for _, sep := range l.thousands {
+		if strings.Contains(integer, sep) {
+			groups = strings.Split(integer, sep)
+			break
+		}
+	}
+	for i, g := range groups {
+		if !isDigits(g) || (i > 0 && len(g) != 3) || (i == 0 && len(groups) > 1 && len(g) > 3) {
+			return "", false, false
+		}
+	}
+	number = strings.Join(groups, "")
+	if fraction == "" {
+		return number, true, true
+	}
+	return number + "." + fraction, false, true
+}
+
+// addFormattedNumber counts formatted number as numeric value.
+func (f *ReportField) addFormattedNumber(v float64, unit string, integral bool, line int) {
+	if f.Minimum == nil {
+		f.Minimum = new(float64)
+		f.Maximum = new(float64)
+		*f.Minimum = v
+		*f.Maximum = v
+	}
+	if v < *f.Minimum {
+		*f.Minimum = v
+	}
+	if v > *f.Maximum {
+		*f.Maximum = v
+	}
+	f.stats.add(v)
+	if f.numDigest == nil {
+		f.numDigest = newTDigest(digestCompression)
+	}
+	f.numDigest.add(v)
+	f.extremes.add(v, line)
+	if integral {
+		f.TypeInt++
+	}
+	f.TypeFloat++
+	f.TypeFormatted++
+	if unit != "" {
+		if f.NumberUnits == nil {
+			f.NumberUnits = make(map[string]int)
+		}
+		f.NumberUnits[unit]++
+	}
+}
+
+// summarizeNumberUnit sets the most used unit of formatted numbers.
+func (f *ReportField) summarizeNumberUnit() {
+	max := 0
+	for unit, count := range f.NumberUnits {
+		if count > max || (count == max && unit < f.NumberUnit) {
+			f.NumberUnit = unit
+			max = count
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumberLocaleParse(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		locale   string
+		s        string
+		v        float64
+		unit     string
+		integral bool
+	}{
+		{"ja-JP", "1,234", 1234, "", true},
+		{"ja-JP", "1,234,567.89", 1234567.89, "", false},
+		{"ja-JP", "¥1,000", 1000, "¥", true},
+		{"ja-JP", "￥1,000", 1000, "¥", true},
+		{"ja-JP", "1,000円", 1000, "円", true},
+		{"ja-JP", "12.5%", 12.5, "%", false},
+		{"ja-JP", "(300)", -300, "", true},
+		{"ja-JP", "▲300", -300, "", true},
+		{"ja-JP", "-¥1,000", -1000, "¥", true},
+		{"ja-JP", "¥-1,000", -1000, "¥", true},
+		{"ja-JP", "１２３", 123, "", true},
+		{"ja-JP", "１，２３４円", 1234, "円", true},
+		{"en-US", "$1,234.50", 1234.5, "$", false},
+		{"en-US", "(1,234.50)", -1234.5, "", false},
+		{"en-US", "5%", 5, "%", true},
+		{"de-DE", "1.234", 1234, "", true},
+		{"de-DE", "1.234,5", 1234.5, "", false},
+		{"de-DE", "12,5 €", 12.5, "€", false},
+		{"de-DE", "-0,5", -0.5, "", false},
+		{"fr-FR", "1 234,5", 1234.5, "", false},
+	} {
+		r, err := lookupNumberRecognizer(tc.locale)
+		a.Nil(err)
+		v, unit, integral, ok := r.parse(tc.s)
+		a.True(ok, "%q should be parsed in %s", tc.s, tc.locale)
+		a.InDelta(tc.v, v, 1e-9, "%q in %s", tc.s, tc.locale)
+		a.Equal(tc.unit, unit, "unit of %q in %s", tc.s, tc.locale)
+		a.Equal(tc.integral, integral, "%q in %s", tc.s, tc.locale)
+	}
+	for _, tc := range []struct {
+		locale string
+		s      string
+	}{
+		{"ja-JP", "1234"},
+		{"ja-JP", "-12.5"},
+		{"ja-JP", "+5"},
+		{"ja-JP", "12,34"},
+		{"ja-JP", "1234,567"},
+		{"ja-JP", "1,234.5.6"},
+		{"ja-JP", "¥"},
+		{"ja-JP", "abc"},
+		{"ja-JP", "$100"},
+		{"ja-JP", "1,234.x"},
+		{"de-DE", "1,234.5"},
+		{"de-DE", "1.5"},
+	} {
+		r, _ := lookupNumberRecognizer(tc.locale)
+		_, _, _, ok := r.parse(tc.s)
+		a.False(ok, "%q should not be parsed in %s", tc.s, tc.locale)
+	}
+}
+
+func TestLookupNumberRecognizer(t *testing.T) {
+	a := assert.New(t)
+	_, err := lookupNumberRecognizer("ja-JP")
+	a.Nil(err)
+	_, err = lookupNumberRecognizer("xx-XX")
+	a.EqualError(err, `number locale should be one of de-DE, en-US, fr-FR, ja-JP, but "xx-XX"`)
+}
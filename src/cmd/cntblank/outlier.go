@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// outlierTracking is the number of the smallest and the largest values
+// tracked to count outliers exactly and show examples of them.
+const outlierTracking = 100
+
+// Outlier is a numeric value which is far from others, and the line
+// number where it appears. The line is physical one of input, which counts
+// header line, blank lines and newlines in quoted fields.
+type Outlier struct {
+	Value float64 `json:"value"`
+	Line  int     `json:"line"`
+}
+
+// extremes keeps the smallest and the largest values in bounded space.
+// Both are sorted from the most extreme one.
+type extremes struct {
+	low  []Outlier
+	high []Outlier
+}
+
+func (e *extremes) add(v float64, line int) {
+	e.low = insertExtreme(e.low, Outlier{v, line}, func(a, b float64) bool { return a < b })
+	e.high = insertExtreme(e.high, Outlier{v, line}, func(a, b float64) bool { return a > b })
+}
+
+// insertExtreme inserts o into s which is sorted by more, and drops the
+// least extreme one when s exceeds outlierTracking.
+func insertExtreme(s []Outlier, o Outlier, more func(a, b float64) bool) []Outlier {
+	if len(s) == outlierTracking && !more(o.Value, s[len(s)-1].Value) {
+		return s
+	}
+	i := sort.Search(len(s), func(i int) bool { return more(o.Value, s[i].Value) })
+	if len(s) < outlierTracking {
+		s = append(s, Outlier{})
+	}
+	copy(s[i+1:], s[i:])
+	s[i] = o
+	return s
+}
+
+// beyond returns tracked values beyond the fences, and the number of
+// values beyond them. The number is exact unless all tracked values on
+// the side are beyond the fence, where it is estimated by the digest.
+func (e *extremes) beyond(lower, upper float64, d *tdigest) (outliers []Outlier, count int) {
+	low := 0
+	for low < len(e.low) && e.low[low].Value < lower {
+		low++
+	}
+	high := 0
+	for high < len(e.high) && e.high[high].Value > upper {
+		high++
+	}
+	outliers = append(append(outliers, e.low[:low]...), e.high[:high]...)
+	count = low + high
+	if low == outlierTracking {
+		if n := int(d.cdf(lower)*d.count + 0.5); n > low {
+			count += n - low
+		}
+	}
+	if high == outlierTracking {
+		if n := int((1-d.cdf(upper))*d.count + 0.5); n > high {
+			count += n - high
+		}
+	}
+	return outliers, count
+}
+
+// summarizeOutliers counts numeric outliers by IQR rule, which is beyond
+// k times interquartile range from quartiles, and by z-score rule, which
+// is beyond z times standard deviation from mean.
+// Examples are the most extreme ones of both rules.
+func (f *ReportField) summarizeOutliers(k, z float64, examples int) {
+	if f.numDigest == nil || f.useTime() {
+		return
+	}
+	q1, q3 := f.numDigest.quantile(0.25), f.numDigest.quantile(0.75)
+	iqr := q3 - q1
+	iqrOutliers, iqrCount := f.extremes.beyond(q1-k*iqr, q3+k*iqr, f.numDigest)
+	f.OutliersIQR = iqrCount
+	var zOutliers []Outlier
+	if sd := f.stats.StdDev(); sd > 0 {
+		mean := f.stats.Mean()
+		zOutliers, f.OutliersZScore = f.extremes.beyond(mean-z*sd, mean+z*sd, f.numDigest)
+	}
+	// Outliers by z-score are usually outliers by IQR, so take the union.
+	seen := make(map[Outlier]bool)
+	all := make([]Outlier, 0, len(iqrOutliers)+len(zOutliers))
+	for _, o := range append(iqrOutliers, zOutliers...) {
+		if !seen[o] {
+			seen[o] = true
+			all = append(all, o)
+		}
+	}
+	sort.Sort(byDistance{all, (q1 + q3) / 2})
+	if len(all) > examples {
+		all = all[:examples]
+	}
+	if len(all) > 0 {
+		f.Outliers = all
+	}
+}
+
+// byDistance sorts outliers by distance from center in descending order.
+type byDistance struct {
+	outliers []Outlier
+	center   float64
+}
+
+func (a byDistance) Len() int      { return len(a.outliers) }
+func (a byDistance) Swap(i, j int) { a.outliers[i], a.outliers[j] = a.outliers[j], a.outliers[i] }
+func (a byDistance) Less(i, j int) bool {
+	di := math.Abs(a.outliers[i].Value - a.center)
+	dj := math.Abs(a.outliers[j].Value - a.center)
+	if di != dj {
+		return di > dj
+	}
+	return a.outliers[i].Line < a.outliers[j].Line
+}
+
+// formatOutliers returns examples of outliers with their line numbers.
+func (r *ReportField) formatOutliers() string {
+	outliers := make([]string, 0, len(r.Outliers))
+	for _, o := range r.Outliers {
+		outliers = append(outliers, fmt.Sprintf("%s@%d", strconv.FormatFloat(o.Value, 'f', -1, 64), o.Line))
+	}
+	return strings.Join(outliers, " ")
+}
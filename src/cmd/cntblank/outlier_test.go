@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtremes(t *testing.T) {
+	a := assert.New(t)
+	var e extremes
+	for i := 0; i < outlierTracking*2; i++ {
+		e.add(float64((i*37)%(outlierTracking*2)), i+1)
+	}
+	a.Equal(outlierTracking, len(e.low))
+	a.Equal(outlierTracking, len(e.high))
+	a.Equal(0.0, e.low[0].Value)
+	a.Equal(1, e.low[0].Line)
+	a.Equal(float64(outlierTracking-1), e.low[outlierTracking-1].Value)
+	a.Equal(float64(outlierTracking*2-1), e.high[0].Value)
+	a.Equal(float64(outlierTracking), e.high[outlierTracking-1].Value)
+}
+
+func TestReportOutliers(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.OutlierExamples = 2
+	report := newReport(File{}, option)
+	report.HasHeader = true
+	for i := 0; i < 50; i++ {
+		report.parseRecord([]string{fmt.Sprint(10 + i%7)})
+	}
+	report.parseRecord([]string{"9999999"})
+	report.parseRecord([]string{"-500"})
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(2, f.OutliersIQR)
+	a.Equal(1, f.OutliersZScore)
+	a.Equal([]Outlier{{9999999, 52}, {-500, 53}}, f.Outliers)
+	a.Equal([]string{"2", "1", "9999999@52 -500@53"}, f.format(report.Records)[54:57])
+
+	report = new(Report)
+	for _, s := range []string{"1", "2", "3", "x"} {
+		report.parseRecord([]string{s})
+	}
+	report.summarize()
+	f = report.Fields[0]
+	a.Equal(0, f.OutliersIQR)
+	a.Equal(0, f.OutliersZScore)
+	a.Nil(f.Outliers)
+}
+
+func TestReportOutliersEstimated(t *testing.T) {
+	a := assert.New(t)
+	report := new(Report)
+	for i := 0; i < 1000; i++ {
+		report.parseRecord([]string{fmt.Sprint(i % 10)})
+	}
+	for i := 0; i < outlierTracking*2; i++ {
+		report.parseRecord([]string{fmt.Sprint(1000000 + i)})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	// All tracked values are beyond the fence, so the count is estimated.
+	a.True(f.OutliersIQR > outlierTracking)
+	a.Equal(3, len(f.Outliers))
+	a.Equal(float64(1000000+outlierTracking*2-1), f.Outliers[0].Value)
+}
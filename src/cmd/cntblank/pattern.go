@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// shape maps each character of s to its class, which is "A" for upper
+// case letters, "a" for lower case letters, "9" for digits, "K" for kana
+// and "H" for kanji. Other characters such as punctuation are kept, so
+// that "03-1234-5678" becomes "99-9999-9999".
+func shape(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsDigit(r):
+			return '9'
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r), r == 'ー', r == 'ｰ':
+			return 'K'
+		case unicode.Is(unicode.Han, r):
+			return 'H'
+		case unicode.IsUpper(r):
+			return 'A'
+		case unicode.IsLower(r):
+			return 'a'
+		}
+		return r
+	}, s)
+}
+
+// summarizePatterns sets the most common patterns and the ratio of
+// non-blank cells covered by them.
+func (f *ReportField) summarizePatterns(records, n int) {
+	if f.patterns == nil {
+		return
+	}
+	f.TopPatterns = f.patterns.top(n)
+	filled := records - f.Blank
+	if filled <= 0 {
+		return
+	}
+	covered := 0
+	for _, p := range f.TopPatterns {
+		covered += p.Count
+	}
+	if covered > filled {
+		covered = filled
+	}
+	f.PatternCoverage = float64(covered) / float64(filled)
+}
+
+// formatPatterns returns the most common patterns with their counts as
+// one string, whose patterns are quoted since they may contain spaces.
+func (r *ReportField) formatPatterns() string {
+	patterns := make([]string, 0, len(r.TopPatterns))
+	for _, p := range r.TopPatterns {
+		patterns = append(patterns, fmt.Sprintf("%q:%d", p.Value, p.Count))
+	}
+	return strings.Join(patterns, " ")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShape(t *testing.T) {
+	a := assert.New(t)
+	for s, want := range map[string]string{
+		"":             "",
+		"100-0001":     "999-9999",
+		"ABC12":        "AAA99",
+		"Tokyo 1":      "Aaaaa 9",
+		"東京都":          "HHH",
+		"とうきょう":        "KKKKK",
+		"トーキョー":        "KKKKK",
+		"ﾄｳｷｮｳ":        "KKKKK",
+		"１２３":          "999",
+		"foo@bar.com":  "aaa@aaa.aaa",
+		"(03)1234":     "(99)9999",
+		"東京タワー333m":    "HHKKK999a",
+		"ÀÉ":           "AA",
+		"2016/01/02 ☃": "9999/99/99 ☃",
+	} {
+		a.Equal(want, shape(s), "shape of %q", s)
+	}
+}
+
+func TestReportPatterns(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.TopPatterns = 2
+	report := newReport(File{}, option)
+	for _, s := range []string{"100-0001", "060-0000", "1000001", "", "100 0001", "530-0001"} {
+		report.parseRecord([]string{s})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(2, len(f.TopPatterns))
+	a.Equal(ValueCount{Value: "999-9999", Count: 3}, f.TopPatterns[0])
+	a.Equal(1, f.TopPatterns[1].Count)
+	a.InDelta(0.8, f.PatternCoverage, 1e-9)
+	r := f.format(report.Records)
+	a.Contains([]string{
+		`"999-9999":3 "9999999":1`,
+		`"999-9999":3 "999 9999":1`,
+	}, r[52])
+	a.Equal("0.8000", r[53])
+
+	option.TopPatterns = 0
+	report = newReport(File{}, option)
+	report.parseRecord([]string{"100-0001"})
+	report.summarize()
+	a.Nil(report.Fields[0].TopPatterns)
+	a.Equal([]string{"", ""}, report.Fields[0].format(report.Records)[52:54])
+}
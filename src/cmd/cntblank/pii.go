@@ -0,0 +1,209 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	valid "github.com/asaskevich/govalidator"
+)
+
+// PIIProfile counts cells which are likely to contain personal data, and
+// keeps the first example of each type with masking.
+type PIIProfile struct {
+	Email        int `json:"email"`        // email address
+	Phone        int `json:"phone"`        // Japanese phone number
+	CreditCard   int `json:"creditCard"`   // credit card number which passes Luhn check
+	MyNumber     int `json:"myNumber"`     // Japanese individual number with check digit
+	KatakanaName int `json:"katakanaName"` // name-like value written in katakana only
+	examples     map[string]string
+}
+
+var (
+	creditCardPattern = regexp.MustCompile(`^[0-9]{4}(?:[- ]?[0-9]{2,7}){2,4}$`)
+	myNumberPattern   = regexp.MustCompile(`^[0-9]{4}[- ]?[0-9]{4}[- ]?[0-9]{4}$`)
+)
+
+// piiType is a pair of the name of PII type and its counter.
+type piiType struct {
+	name  string
+	count func(p *PIIProfile) *int
+	match func(string) bool
+	mask  func(string) string
+}
+
+// piiTypes are PII types in order of examination.
+var piiTypes = []piiType{
+	{"email", func(p *PIIProfile) *int { return &p.Email }, valid.IsEmail, maskEmail},
+	{"my_number", func(p *PIIProfile) *int { return &p.MyNumber }, isMyNumber, maskDigits},
+	{"credit_card", func(p *PIIProfile) *int { return &p.CreditCard }, isCreditCard, maskDigits},
+	{"phone", func(p *PIIProfile) *int { return &p.Phone }, isPhoneJP, maskDigits},
+	{"katakana_name", func(p *PIIProfile) *int { return &p.KatakanaName }, isKatakanaName, maskName},
+}
+
+func (p *PIIProfile) add(s string) {
+	for _, c := range piiTypes {
+		if !c.match(s) {
+			continue
+		}
+		*c.count(p)++
+		if p.examples == nil {
+			p.examples = make(map[string]string)
+		}
+		if _, ok := p.examples[c.name]; !ok {
+			p.examples[c.name] = c.mask(s)
+		}
+		return
+	}
+}
+
+// dominant returns the name and count of the most frequent PII type, and
+// masked example of it.
+func (p *PIIProfile) dominant() (name string, count int, example string) {
+	for _, c := range piiTypes {
+		if n := *c.count(p); n > count {
+			name, count = c.name, n
+		}
+	}
+	return name, count, p.examples[name]
+}
+
+// isCreditCard reports whether s is 13 to 19 digits which pass Luhn check,
+// allowing separators of space or hyphen.
+func isCreditCard(s string) bool {
+	if !creditCardPattern.MatchString(s) {
+		return false
+	}
+	digits := stripSeparators(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// isMyNumber reports whether s is 12 digits of Japanese individual number
+// whose last digit is the check digit.
+func isMyNumber(s string) bool {
+	if !myNumberPattern.MatchString(s) {
+		return false
+	}
+	digits := stripSeparators(s)
+	sum := 0
+	for n := 1; n <= 11; n++ {
+		p := int(digits[11-n] - '0')
+		q := n + 1
+		if n > 6 {
+			q = n - 5
+		}
+		sum += p * q
+	}
+	check := 0
+	if r := sum % 11; r > 1 {
+		check = 11 - r
+	}
+	return int(digits[11]-'0') == check
+}
+
+// isKatakanaName reports whether s consists of katakana, which is often
+// used for reading of personal names such as "ヤマダ タロウ".
+func isKatakanaName(s string) bool {
+	n := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Katakana, r), r == 'ー', r == 'ｰ', r == '・', r == '･':
+			n++
+		case r == ' ', r == ideographicSpace, r == 'ﾞ', r == 'ﾟ':
+		default:
+			return false
+		}
+	}
+	return n >= 2
+}
+
+func stripSeparators(s string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(s)
+}
+
+// maskDigits replaces digits with "*" except the last four of them.
+func maskDigits(s string) string {
+	keep := 4
+	b := []byte(s)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '0' || b[i] > '9' {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		b[i] = '*'
+	}
+	return string(b)
+}
+
+// maskEmail replaces local part of email address with "*" except the
+// first character, and keeps domain part.
+func maskEmail(s string) string {
+	i := strings.LastIndex(s, "@")
+	if i < 1 {
+		return maskName(s)
+	}
+	return maskName(s[:i]) + s[i:]
+}
+
+// maskName replaces characters with "*" except the first one.
+func maskName(s string) string {
+	_, size := utf8.DecodeRuneInString(s)
+	return s[:size] + strings.Repeat("*", utf8.RuneCountInString(s[size:]))
+}
+
+// summarizePII sets the ratio of the most frequent PII type to non-blank
+// cells. The field is flagged as the type with masked example only when
+// the ratio reaches threshold, since a few cells match by chance such as
+// Luhn-valid prices. Sparse matches are left as counts in PII.
+// Threshold of zero, which is left unset, falls back to the default.
+func (f *ReportField) summarizePII(records int, threshold float64) {
+	if f.PII == nil {
+		return
+	}
+	if threshold <= 0 {
+		threshold = defaultProfileOption.PIIThreshold
+	}
+	name, count, example := f.PII.dominant()
+	filled := records - f.Blank
+	if count == 0 || filled <= 0 {
+		return
+	}
+	f.PIIRatio = float64(count) / float64(filled)
+	if f.PIIRatio < threshold {
+		return
+	}
+	f.PIIType = name
+	f.PIIExample = example
+}
+
+// redactValues drops statistics of the field flagged as PII which expose
+// raw values, that is top values, range, moments, quantiles, histogram and
+// examples of outliers. Counts and patterns are kept.
+func (f *ReportField) redactValues() {
+	f.TopValues = nil
+	f.Minimum, f.Maximum = nil, nil
+	f.MinTime, f.MaxTime = nil, nil
+	f.Mean, f.Variance, f.StdDev, f.Sum = nil, nil, nil, nil
+	f.Median, f.MedianTime = nil, nil
+	f.Quantiles = nil
+	f.Histogram = nil
+	f.Outliers = nil
+}
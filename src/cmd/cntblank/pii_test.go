@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPIIProfile(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		s       string
+		want    string
+		example string
+	}{
+		{"foo@example.com", "email", "f**@example.com"},
+		{"123456789018", "my_number", "********9018"},
+		{"1234-5678-9018", "my_number", "****-****-9018"},
+		{"4111111111111111", "credit_card", "************1111"},
+		{"4111 1111 1111 1111", "credit_card", "**** **** **** 1111"},
+		{"5500-0000-0000-0004", "credit_card", "****-****-****-0004"},
+		{"03-1234-5678", "phone", "**-****-5678"},
+		{"09012345678", "phone", "*******5678"},
+		{"ヤマダ タロウ", "katakana_name", "ヤ******"},
+		{"ﾔﾏﾀﾞ ﾀﾛｳ", "katakana_name", "ﾔ*******"},
+		{"123456789012", "", ""},
+		{"4111111111111112", "", ""},
+		{"1234", "", ""},
+		{"山田太郎", "", ""},
+		{"ア", "", ""},
+		{"", "", ""},
+	} {
+		p := new(PIIProfile)
+		p.add(tc.s)
+		name, count, example := p.dominant()
+		a.Equal(tc.want, name, "PII type of %q", tc.s)
+		a.Equal(tc.example, example, "example of %q", tc.s)
+		if tc.want != "" {
+			a.Equal(1, count)
+		}
+	}
+}
+
+func TestIsMyNumber(t *testing.T) {
+	a := assert.New(t)
+	a.True(isMyNumber("111111111118"))
+	a.True(isMyNumber("987654321018"))
+	a.False(isMyNumber("987654321019"))
+	a.False(isMyNumber("98765432101"))
+	a.False(isMyNumber("9876543210188"))
+}
+
+func TestMask(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("*2345", maskDigits("12345"))
+	a.Equal("+**-*-****-5678", maskDigits("+81-3-1234-5678"))
+	a.Equal("a**", maskName("abc"))
+	a.Equal("山*", maskName("山田"))
+	a.Equal("@**", maskEmail("@ab"))
+}
+
+func TestReportPII(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.DetectPII = true
+	report := newReport(File{}, option)
+	for _, s := range [][]string{
+		{"foo@example.com", "1"},
+		{"bar@example.com", "2"},
+		{"n/a", ""},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(2, f.PII.Email)
+	a.Equal("email", f.PIIType)
+	a.Equal([]string{"email", "0.6667", "f**@example.com"}, f.format(report.Records)[49:52])
+	f = report.Fields[1]
+	a.NotNil(f.PII)
+	a.Equal("", f.PIIType)
+
+	report = new(Report)
+	report.parseRecord([]string{"foo@example.com"})
+	report.summarize()
+	a.Nil(report.Fields[0].PII, "PII detection should be opt-in")
+}
+
+func TestReportPIIRedaction(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.DetectPII = true
+	option.DetectEpoch = true
+	report := newReport(File{}, option)
+	raws := []string{"4012888888881881", "4111111111111111", "5500005555555559"}
+	for _, s := range raws {
+		report.parseRecord([]string{s, "090-1234-5678"})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal("credit_card", f.PIIType)
+	a.Nil(f.TopValues)
+	a.Nil(f.Minimum)
+	a.Nil(f.Quantiles)
+	r := f.format(report.Records)
+	a.Equal([]string{"", ""}, r[17:19])
+	b, err := json.Marshal(report)
+	a.Nil(err)
+	out := string(b) + strings.Join(r, ",") + strings.Join(report.Fields[1].format(report.Records), ",")
+	for _, s := range append(raws, "1234-5678", "4.012888888881881e+15", "4012888888881881.0000") {
+		a.NotContains(out, s)
+	}
+}
+
+func TestReportPIISparse(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.DetectPII = true
+	report := newReport(File{}, option)
+	report.parseRecord([]string{"4111111111111111", "ナシ"})
+	for i := 1; i < 100; i++ {
+		report.parseRecord([]string{fmt.Sprint(i * 100), "特になし"})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(1, f.PII.CreditCard)
+	a.Equal("", f.PIIType, "sparse match should not flag field")
+	a.Equal(0.01, f.PIIRatio)
+	a.NotNil(f.Minimum)
+	a.Equal(100.0, *f.Minimum)
+	a.NotNil(f.TopValues)
+	a.Equal([]string{"", "0.0100", ""}, f.format(report.Records)[49:52])
+	f = report.Fields[1]
+	a.Equal(1, f.PII.KatakanaName)
+	a.Equal("", f.PIIType)
+
+	option.PIIThreshold = 0.01
+	report = newReport(File{}, option)
+	report.parseRecord([]string{"4111111111111111"})
+	report.parseRecord([]string{"100"})
+	report.summarize()
+	a.Equal("credit_card", report.Fields[0].PIIType)
+	a.Nil(report.Fields[0].Minimum)
+}
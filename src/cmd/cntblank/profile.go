@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProfileOption is a configuration how to profile values in each field.
+type ProfileOption struct {
+	Percentiles       []float64 // percentiles to estimate in addition to median
+	DistinctThreshold int       // count distinct values exactly up to this number
+	TopValues         int       // number of most frequent values to report
+	TopPatterns       int       // number of most common patterns to report
+	LengthBuckets     []int     // upper bounds of buckets of length histogram
+	HistogramBins     int       // number of bins of histogram of numbers and times
+	OutlierIQR        float64   // multiplier of interquartile range to detect outliers
+	OutlierZScore     float64   // z-score to detect outliers
+	OutlierExamples   int       // number of outliers to report as examples
+	TypeThreshold     float64   // ratio of non-blank cells to infer data type
+	TimeLayouts       []string  // layouts tried before default ones
+	TimeLayoutOnly    bool      // use only TimeLayouts to parse time values
+	Location          *time.Location
+	DetectEpoch       bool    // detect numeric timestamps such as Unix epoch
+	NumberLocale      string  // locale to recognize formatted numbers such as "ja-JP"
+	DetectPII         bool    // detect personal data such as email and phone number
+	PIIThreshold      float64 // ratio of non-blank cells to flag field as PII
+}
+
+var defaultProfileOption = ProfileOption{
+	Percentiles:       []float64{1, 5, 25, 75, 95, 99},
+	DistinctThreshold: 10000,
+	TopValues:         5,
+	TopPatterns:       5,
+	LengthBuckets:     []int{8, 16, 32, 64, 128, 256},
+	HistogramBins:     10,
+	OutlierIQR:        1.5,
+	OutlierZScore:     3,
+	OutlierExamples:   3,
+	TypeThreshold:     0.95,
+	PIIThreshold:      0.5,
+}
+
+// NewProfileOption creates new ProfileOption instance with default values.
+func NewProfileOption() *ProfileOption {
+	o := defaultProfileOption
+	o.Percentiles = append([]float64(nil), defaultProfileOption.Percentiles...)
+	o.LengthBuckets = append([]int(nil), defaultProfileOption.LengthBuckets...)
+	return &o
+}
+
+// Validate checks each option is in valid range.
+func (o *ProfileOption) Validate() error {
+	for _, p := range o.Percentiles {
+		if p <= 0 || p >= 100 {
+			return fmt.Errorf("percentile should be between 0 and 100, but %v", p)
+		}
+	}
+	if o.DistinctThreshold < 0 {
+		return fmt.Errorf("distinct threshold should not be negative, but %d", o.DistinctThreshold)
+	}
+	if o.TypeThreshold <= 0 || o.TypeThreshold > 1 {
+		return fmt.Errorf("type threshold should be greater than 0 and up to 1, but %v", o.TypeThreshold)
+	}
+	if o.PIIThreshold <= 0 || o.PIIThreshold > 1 {
+		return fmt.Errorf("PII threshold should be greater than 0 and up to 1, but %v", o.PIIThreshold)
+	}
+	if o.TopValues < 0 {
+		return fmt.Errorf("number of top values should not be negative, but %d", o.TopValues)
+	}
+	for i, b := range o.LengthBuckets {
+		if b <= 0 || (i > 0 && b <= o.LengthBuckets[i-1]) {
+			return fmt.Errorf("length buckets should be positive and increasing, but %v", o.LengthBuckets)
+		}
+	}
+	if o.HistogramBins < 0 {
+		return fmt.Errorf("number of histogram bins should not be negative, but %d", o.HistogramBins)
+	}
+	if o.OutlierIQR <= 0 || o.OutlierZScore <= 0 {
+		return fmt.Errorf("thresholds of outliers should be positive, but IQR %v and z-score %v", o.OutlierIQR, o.OutlierZScore)
+	}
+	if o.OutlierExamples < 0 {
+		return fmt.Errorf("number of outlier examples should not be negative, but %d", o.OutlierExamples)
+	}
+	if o.TopPatterns < 0 {
+		return fmt.Errorf("number of top patterns should not be negative, but %d", o.TopPatterns)
+	}
+	if o.NumberLocale != "" {
+		if _, err := lookupNumberRecognizer(o.NumberLocale); err != nil {
+			return err
+		}
+	}
+	if o.TimeLayoutOnly && len(o.TimeLayouts) == 0 {
+		return fmt.Errorf("time layout should be given to replace default ones")
+	}
+	return nil
+}
+
+// timeParser creates the parser with layouts and location of the option.
+func (o *ProfileOption) timeParser() *timeParser {
+	p := &timeParser{
+		layouts:  o.TimeLayouts,
+		location: o.Location,
+	}
+	if !o.TimeLayoutOnly {
+		p.wareki = true
+		p.layouts = append(append([]string(nil), o.TimeLayouts...), timeLayouts...)
+	}
+	if p.location == nil {
+		p.location = time.UTC
+	}
+	return p
+}
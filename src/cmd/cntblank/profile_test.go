@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProfileOption(t *testing.T) {
+	a := assert.New(t)
+	o := NewProfileOption()
+	a.Equal([]float64{1, 5, 25, 75, 95, 99}, o.Percentiles)
+	a.Nil(o.Validate())
+	o.Percentiles[0] = 2
+	a.Equal(1.0, defaultProfileOption.Percentiles[0], "default should not be modified")
+}
+
+func TestProfileOptionValidate(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		percentiles []float64
+		valid       bool
+	}{
+		{nil, true},
+		{[]float64{0.1, 99.9}, true},
+		{[]float64{0}, false},
+		{[]float64{100}, false},
+		{[]float64{50, -1}, false},
+	} {
+		o := NewProfileOption()
+		o.Percentiles = tc.percentiles
+		if tc.valid {
+			a.Nil(o.Validate(), "%v should be valid", tc.percentiles)
+		} else {
+			a.NotNil(o.Validate(), "%v should be invalid", tc.percentiles)
+		}
+	}
+	o := NewProfileOption()
+	o.NumberLocale = "xx-XX"
+	a.NotNil(o.Validate(), "unknown number locale should be invalid")
+	for _, buckets := range [][]int{{0}, {8, 8}, {16, 8}} {
+		o = NewProfileOption()
+		o.LengthBuckets = buckets
+		a.NotNil(o.Validate(), "length buckets %v should be invalid", buckets)
+	}
+	for _, tc := range []struct {
+		iqr, z float64
+	}{{0, 3}, {1.5, 0}, {-1, 3}} {
+		o = NewProfileOption()
+		o.OutlierIQR, o.OutlierZScore = tc.iqr, tc.z
+		a.NotNil(o.Validate(), "outlier thresholds %v should be invalid", tc)
+	}
+	o = NewProfileOption()
+	o.TopPatterns = -1
+	a.NotNil(o.Validate(), "negative top patterns should be invalid")
+	o = NewProfileOption()
+	o.TimeLayoutOnly = true
+	a.NotNil(o.Validate(), "time layout only needs layouts")
+	o.TimeLayouts = []string{"02/01/2006"}
+	a.Nil(o.Validate())
+	for _, threshold := range []float64{0, -0.5, 1.01} {
+		o := &ProfileOption{TypeThreshold: threshold}
+		a.NotNil(o.Validate(), "type threshold %v should be invalid", threshold)
+	}
+}
+
+func TestProfileOptionTimeParser(t *testing.T) {
+	a := assert.New(t)
+	o := NewProfileOption()
+	p := o.timeParser()
+	a.Equal(timeLayouts, p.layouts)
+	a.Equal(time.UTC, p.location)
+	o.TimeLayouts = []string{"02/01/2006"}
+	p = o.timeParser()
+	a.Equal(len(timeLayouts)+1, len(p.layouts))
+	a.Equal("02/01/2006", p.layouts[0])
+	a.Equal(len(defaultProfileOption.TimeLayouts), 0, "default should not be modified")
+	o.TimeLayoutOnly = true
+	a.Equal([]string{"02/01/2006"}, o.timeParser().layouts)
+}
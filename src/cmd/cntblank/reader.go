@@ -1,11 +1,16 @@
 package main
 
 import (
+	"crypto/md5"
 	"encoding/csv"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/tealeg/xlsx"
@@ -15,14 +20,21 @@ import (
 
 // Reader is a generic file reader.
 type Reader struct {
-	path      string
-	line      int
-	columns   map[int]int
-	err       int
-	fp        *os.File
-	csvReader *csv.Reader
-	slices    [][]string
-	logger    *log.Entry
+	path       string
+	line       int
+	recordLine int // physical line where the last record starts
+	columns    map[int]int
+	err        int
+	fp         io.Closer
+	csvReader  *csv.Reader
+	lines      *csvhelper.LineCounter
+	slices     [][]string
+	encoding   string
+	hasBOM     bool
+	dialect    *csvhelper.FileDialect
+	raw        io.Reader // file as it is, which feeds hasher
+	hasher     hash.Hash
+	logger     *log.Entry
 }
 
 // NewReader returns a new Reader that reads from r using dialect.
@@ -31,18 +43,85 @@ func NewReader(r io.Reader, dialect *csvhelper.FileDialect) (reader *Reader, err
 		columns: make(map[int]int),
 		logger:  log.WithFields(nil),
 	}
-	reader.csvReader = csvhelper.NewCsvReader(r, dialect)
+	// Resolve encoding by BOM or detection before creating csv reader,
+	// so that the encoding is reported.
+	r, bom, err := csvhelper.SkipBOM(r)
+	if err != nil {
+		return nil, err
+	}
+	d := *dialect
+	if bom != "" {
+		d.Encoding = bom
+		reader.hasBOM = true
+	} else if d.Encoding == csvhelper.EncodingAuto {
+		r, d.Encoding, err = csvhelper.DetectEncoding(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if d.Sniff {
+		var sniffed *csvhelper.FileDialect
+		r, sniffed, err = csvhelper.SniffDialect(r, &d)
+		if err != nil {
+			return nil, err
+		}
+		// Header line is not sniffed when it is disabled explicitly.
+		sniffed.HasHeader = sniffed.HasHeader && d.HasHeader
+		d = *sniffed
+	}
+	dialect = &d
+	reader.encoding = dialect.Encoding
+	reader.dialect = dialect
+	reader.csvReader, reader.lines = csvhelper.NewCsvLineReader(r, dialect)
 	return
 }
 
 // OpenFile returns a new Reader that reads from path using dialect.
 func OpenFile(path string, dialect *csvhelper.FileDialect) (reader *Reader, err error) {
+	return openFile(path, dialect, nil)
+}
+
+// openFile returns a new Reader that reads from path opened by opener.
+// MD5 checksum of the file is calculated while reading it.
+func openFile(path string, dialect *csvhelper.FileDialect, opener *archiveOpener) (reader *Reader, err error) {
 	if path == "" {
-		return NewReader(os.Stdin, dialect)
+		r, _, err := decompress(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		reader, err = NewReader(r, dialect)
+		if err != nil {
+			return nil, err
+		}
+		reader.fp = r
+		return reader, nil
 	}
-	extension := filepath.Ext(path)
+	fp, err := opener.open(path)
+	// TODO: Check `fp` is file or directory.
+	// http://www.reddit.com/r/golang/comments/2fjwyk/isdir_in_go/
+	if err != nil {
+		return nil, err
+	}
+	hasher := md5.New()
+	raw := io.TeeReader(fp, hasher)
+	r, compression, err := decompress(raw)
+	if err != nil {
+		fp.Close()
+		return nil, err
+	}
+	extension := strings.ToLower(filepath.Ext(trimCompressionExt(path)))
 	if extension == ".xlsx" {
-		slices, err := xlsx.FileToSlice(path)
+		// Excel file is ZIP archive, which needs random access.
+		b, err := ioutil.ReadAll(r)
+		multiCloser{fp, r}.Close()
+		if err != nil {
+			return nil, err
+		}
+		file, err := xlsx.OpenBinary(b)
+		if err != nil {
+			return nil, err
+		}
+		slices, err := file.ToSlice()
 		if err != nil {
 			return nil, err
 		}
@@ -59,19 +138,30 @@ func OpenFile(path string, dialect *csvhelper.FileDialect) (reader *Reader, err
 		reader = &Reader{
 			columns: make(map[int]int),
 			slices:  feeds,
+			dialect: dialect,
 		}
 	} else {
-		fp, err := os.Open(path)
-		// TODO: Check `fp` is file or directory.
-		// http://www.reddit.com/r/golang/comments/2fjwyk/isdir_in_go/
+		reader, err = NewReader(r, dialect)
 		if err != nil {
+			multiCloser{fp, r}.Close()
 			return nil, err
 		}
-		reader, err = NewReader(fp, dialect)
-		reader.fp = fp
+		reader.fp = multiCloser{fp, r}
 	}
 	reader.path = path
+	reader.raw = raw
+	reader.hasher = hasher
 	reader.logger = log.WithFields(log.Fields{"path": path})
+	if compression != "" {
+		reader.logger.Debugf("decompress %s stream", compression)
+	}
+	if dialect.Encoding == csvhelper.EncodingAuto && reader.encoding != "" {
+		reader.logger.Infof("detect encoding %s", reader.encoding)
+	}
+	if dialect.Sniff && reader.csvReader != nil {
+		reader.logger.Infof("sniff delimiter %q, quoting %s and header %t",
+			reader.dialect.Comma, reader.dialect.Quoting, reader.dialect.HasHeader)
+	}
 	return
 }
 
@@ -88,7 +178,7 @@ func (r *Reader) Read() (record []string, err error) {
 			}
 			return nil, err
 		} else if err != nil {
-			r.logger.Error(err, ", #line", r.line)
+			r.logger.Error(err, ", #line", r.lines.Line())
 			r.err++
 			if r.err > 100 {
 				r.logger.Error("too many error lines")
@@ -104,6 +194,11 @@ func (r *Reader) Read() (record []string, err error) {
 		record = r.slices[r.line]
 	}
 	r.line++
+	if r.lines != nil {
+		r.recordLine = r.lines.RecordLine(record)
+	} else {
+		r.recordLine = r.line
+	}
 	length := len(record)
 	_, ok := r.columns[length]
 	if ok {
@@ -118,6 +213,18 @@ func (r *Reader) Read() (record []string, err error) {
 	return record, nil
 }
 
+// Checksum returns MD5 checksum of the file as hex string. The rest of the
+// file which is not read yet is read to calculate it.
+func (r *Reader) Checksum() (string, error) {
+	if r.hasher == nil {
+		return "", fmt.Errorf("checksum is not available without path")
+	}
+	if _, err := io.Copy(ioutil.Discard, r.raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(r.hasher.Sum(nil)), nil
+}
+
 // Close closes a internal file pointer.
 func (r *Reader) Close() {
 	if r.fp != nil {
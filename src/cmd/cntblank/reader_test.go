@@ -3,8 +3,12 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"csvhelper"
 )
 
@@ -138,3 +142,68 @@ func TestExcelReaderExceedSheetNumber(t *testing.T) {
 		t.Fatalf("%d should exceed acutual sheet number", dialect.SheetNumber)
 	}
 }
+
+func TestReaderDetectEncoding(t *testing.T) {
+	a := assert.New(t)
+	e, err := csvhelper.LookupEncoding("euc-jp")
+	require.Nil(t, err)
+	encoded, err := e.NewEncoder().String(strings.Repeat("都道府県コード\t都道府県\n13\t東京都\n", 20))
+	require.Nil(t, err)
+	dialect := &csvhelper.FileDialect{
+		Comma:    '\t',
+		Encoding: csvhelper.EncodingAuto,
+	}
+	reader, err := NewReader(strings.NewReader(encoded), dialect)
+	require.Nil(t, err)
+	a.Equal("euc-jp", reader.encoding)
+	a.Equal(csvhelper.EncodingAuto, dialect.Encoding, "given dialect should not be changed")
+	record, err := reader.Read()
+	a.Nil(err)
+	a.Equal([]string{"都道府県コード", "都道府県"}, record)
+
+	reader, err = NewReader(strings.NewReader("a\tb\n"), &csvhelper.FileDialect{Comma: '\t', Encoding: "sjis"})
+	require.Nil(t, err)
+	a.Equal("sjis", reader.encoding)
+}
+
+func TestReaderBOM(t *testing.T) {
+	a := assert.New(t)
+	for _, encoding := range []string{"utf8", "sjis", csvhelper.EncodingAuto} {
+		reader, err := NewReader(strings.NewReader("\xef\xbb\xbfid\tname\n1\t東京\n"), &csvhelper.FileDialect{
+			Comma:    '\t',
+			Encoding: encoding,
+		})
+		require.Nil(t, err)
+		a.True(reader.hasBOM, encoding)
+		a.Equal("utf8", reader.encoding, encoding)
+		record, err := reader.Read()
+		a.Nil(err)
+		a.Equal([]string{"id", "name"}, record, "BOM should be stripped")
+	}
+	reader, err := NewReader(strings.NewReader("id\tname\n"), &csvhelper.FileDialect{Comma: '\t'})
+	require.Nil(t, err)
+	a.False(reader.hasBOM)
+}
+
+func TestReaderChecksum(t *testing.T) {
+	a := assert.New(t)
+	path, err := getTestfilePath("prefecture_jp.tsv")
+	require.Nil(t, err)
+	file := File{path: path}
+	expected, err := file.Checksum()
+	require.Nil(t, err)
+	reader, err := OpenFile(path, &csvhelper.FileDialect{Comma: '\t'})
+	require.Nil(t, err)
+	defer reader.Close()
+	_, err = reader.Read()
+	a.Nil(err)
+	// The rest of file is read for checksum.
+	md5hex, err := reader.Checksum()
+	a.Nil(err)
+	a.Equal(expected, md5hex)
+
+	reader, err = NewReader(strings.NewReader("a\n"), &csvhelper.FileDialect{Comma: ','})
+	require.Nil(t, err)
+	_, err = reader.Checksum()
+	a.NotNil(err, "checksum needs path")
+}
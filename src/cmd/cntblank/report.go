@@ -2,12 +2,13 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
-	valid "github.com/asaskevich/govalidator"
+	"csvhelper"
 )
 
 // Report presents tabular contents description.
@@ -15,109 +16,318 @@ type Report struct {
 	Path      string         `json:"path,omitempty"`
 	Filename  string         `json:"filename,omitempty"`
 	MD5hex    string         `json:"md5,omitempty"`
+	Encoding  string         `json:"encoding,omitempty"`
+	HasBOM    bool           `json:"hasBOM,omitempty"`
+	Delimiter string         `json:"delimiter,omitempty"`
+	Quoting   string         `json:"quoting,omitempty"`
 	HasHeader bool           `json:"header"`
 	Records   int            `json:"records"`
 	Fields    []*ReportField `json:"fields"`
+	option    *ProfileOption
+	parser    *timeParser
+	number    numberRecognizer
+	dialect   *csvhelper.FileDialect
 }
 
 // ReportField represents output field.
 type ReportField struct {
-	Name      string     `json:"name"`
-	Blank     int        `json:"blank"`
-	MinLength int        `json:"minLength"`
-	MaxLength int        `json:"maxLength"`
-	Minimum   *float64   `json:"minimum,omitempty"`
-	Maximum   *float64   `json:"maximum,omitempty"`
-	MinTime   *time.Time `json:"minTime,omitempty"`
-	MaxTime   *time.Time `json:"maxTime,omitempty"`
-	BoolTrue  *int       `json:"boolTrue,omitempty"`
-	BoolFalse *int       `json:"boolFalse,omitempty"`
-	TypeInt   int        `json:"typeInt,omitempty"`
-	TypeFloat int        `json:"typeFloat,omitempty"`
-	TypeBool  int        `json:"typeBool,omitempty"`
-	TypeTime  int        `json:"typeTime,omitempty"`
-	fullWidth int
+	Name              string           `json:"name"`
+	Blank             int              `json:"blank"`
+	Empty             int              `json:"empty"`
+	WhiteSpace        int              `json:"whiteSpace"`
+	NullToken         int              `json:"nullToken"`
+	Padded            int              `json:"padded"`
+	IdeographicSpace  int              `json:"ideographicSpace"`
+	MinLength         int              `json:"minLength"`
+	MaxLength         int              `json:"maxLength"`
+	MeanLength        float64          `json:"meanLength,omitempty"`
+	MaxByteLength     int              `json:"maxByteLength,omitempty"`
+	MaxSJISByteLength int              `json:"maxSjisByteLength,omitempty"`
+	LengthHistogram   []LengthBucket   `json:"lengthHistogram,omitempty"`
+	Minimum           *float64         `json:"minimum,omitempty"`
+	Maximum           *float64         `json:"maximum,omitempty"`
+	MinTime           *time.Time       `json:"minTime,omitempty"`
+	MaxTime           *time.Time       `json:"maxTime,omitempty"`
+	BoolTrue          *int             `json:"boolTrue,omitempty"`
+	BoolFalse         *int             `json:"boolFalse,omitempty"`
+	TypeInt           int              `json:"typeInt,omitempty"`
+	TypeFloat         int              `json:"typeFloat,omitempty"`
+	TypeBool          int              `json:"typeBool,omitempty"`
+	TypeTime          int              `json:"typeTime,omitempty"`
+	TypeWareki        int              `json:"typeWareki,omitempty"`
+	TypeCode          int              `json:"typeCode,omitempty"`
+	TypeFormatted     int              `json:"typeFormatted,omitempty"`
+	NumberUnits       map[string]int   `json:"numberUnits,omitempty"`
+	NumberUnit        string           `json:"numberUnit,omitempty"`
+	EpochUnit         string           `json:"epochUnit,omitempty"`
+	InferredType      DataType         `json:"inferredType,omitempty"`
+	TypeConfidence    float64          `json:"typeConfidence"`
+	TimeLayouts       map[string]int   `json:"timeLayouts,omitempty"`
+	TimeLayout        string           `json:"timeLayout,omitempty"`
+	MixedTimeLayouts  bool             `json:"mixedTimeLayouts,omitempty"`
+	Mean              *float64         `json:"mean,omitempty"`
+	Variance          *float64         `json:"variance,omitempty"`
+	StdDev            *float64         `json:"stddev,omitempty"`
+	Sum               *float64         `json:"sum,omitempty"`
+	Median            *float64         `json:"median,omitempty"`
+	MedianTime        *time.Time       `json:"medianTime,omitempty"`
+	Quantiles         []Quantile       `json:"quantiles,omitempty"`
+	Distinct          int              `json:"distinct"`
+	DistinctRatio     float64          `json:"distinctRatio"`
+	DistinctApprox    bool             `json:"distinctApprox,omitempty"`
+	CandidateKey      bool             `json:"candidateKey,omitempty"`
+	TopValues         []ValueCount     `json:"topValues,omitempty"`
+	TopPatterns       []ValueCount     `json:"topPatterns,omitempty"`
+	PatternCoverage   float64          `json:"patternCoverage,omitempty"`
+	Histogram         []HistogramBin   `json:"histogram,omitempty"`
+	OutliersIQR       int              `json:"outliersIQR,omitempty"`
+	OutliersZScore    int              `json:"outliersZScore,omitempty"`
+	Outliers          []Outlier        `json:"outliers,omitempty"`
+	Characters        CharacterProfile `json:"characters"`
+	Semantics         SemanticProfile  `json:"semantics"`
+	SemanticType      string           `json:"semanticType,omitempty"`
+	SemanticRatio     float64          `json:"semanticRatio,omitempty"`
+	PII               *PIIProfile      `json:"pii,omitempty"`
+	PIIType           string           `json:"piiType,omitempty"`
+	PIIRatio          float64          `json:"piiRatio,omitempty"`
+	PIIExample        string           `json:"piiExample,omitempty"`
+	stats             runningStats
+	numDigest         *tdigest
+	timeDigest        *tdigest
+	distinct          distinctCounter
+	topValues         *spaceSaving
+	patterns          *spaceSaving
+	totalLength       int
+	lengthCounts      []int
+	extremes          extremes
+	typeDate          int
+	ambiguousLayouts  map[string]int    // layouts whose padding is not observed
+	layoutBases       map[string]string // observed layouts to matched ones
+	typeDigits        int               // cells consisting of digits only
+	digitLength       int               // length of digits, or -1 if not fixed
 }
 
+// Quantile represents estimated value at given percentile.
+type Quantile struct {
+	Percentile float64    `json:"percentile"`
+	Value      *float64   `json:"value,omitempty"`
+	Time       *time.Time `json:"time,omitempty"`
+}
+
+// digestCompression is a parameter of t-digest to trade accuracy for memory.
+const digestCompression = 100
+
+// topValuesFactor is a ratio of monitored values to reported values,
+// which improves accuracy of top values.
+const topValuesFactor = 10
+
 func (r ReportField) header() []string {
 	return []string{
 		"seq",
 		"Name",
 		"#Blank",
 		"%Blank",
+		"#Empty",
+		"#WhiteSpace",
+		"#NullToken",
+		"#Padded",
+		"#IdeographicSpace",
 		"MinLength",
 		"MaxLength",
 		"#Int",
 		"#Float",
 		"#Bool",
 		"#Time",
+		"Type",
+		"%Type",
 		"Minimum",
 		"Maximum",
 		"#True",
 		"#False",
+		"Mean",
+		"Variance",
+		"StdDev",
+		"Sum",
+		"Median",
+		"Quantiles",
+		"#Distinct",
+		"%Distinct",
+		"Key",
+		"#FullWidth",
+		"#HalfWidthKana",
+		"#Hiragana",
+		"#Kanji",
+		"#ASCII",
+		"#Digits",
+		"#Control",
+		"#Replacement",
+		"TimeLayout",
+		"MixedLayout",
+		"#Wareki",
+		"Epoch",
+		"EpochMin",
+		"EpochMax",
+		"#Code",
+		"#Formatted",
+		"Unit",
+		"Semantic",
+		"%Semantic",
+		"PII",
+		"%PII",
+		"PIIExample",
+		"Patterns",
+		"%Pattern",
+		"#OutlierIQR",
+		"#OutlierZ",
+		"Outliers",
 	}
 }
 
 func (r *ReportField) format(total int) []string {
-	s := make([]string, 14)
-	s[1] = r.Name
-	s[2] = fmt.Sprint(r.Blank)
+	s := make([]string, 0, 57)
+	s = append(s, "", r.Name)
+	s = append(s, fmt.Sprint(r.Blank))
 	ratio := float64(r.Blank) / float64(total)
-	s[3] = fmt.Sprintf("%.4f", ratio)
-	if r.MinLength > 0 {
-		s[4] = fmt.Sprint(r.MinLength)
+	s = append(s, fmt.Sprintf("%.4f", ratio))
+	s = append(s, formatCount(r.Empty), formatCount(r.WhiteSpace), formatCount(r.NullToken))
+	s = append(s, formatCount(r.Padded), formatCount(r.IdeographicSpace))
+	s = append(s, formatCount(r.MinLength), formatCount(r.MaxLength))
+	s = append(s, formatCount(r.TypeInt), formatCount(r.TypeFloat),
+		formatCount(r.TypeBool), formatCount(r.TypeTime))
+	if r.InferredType != UnknownType {
+		s = append(s, r.InferredType.String(), fmt.Sprintf("%.4f", r.TypeConfidence))
 	} else {
-		s[4] = ""
+		s = append(s, "", "")
 	}
-	if r.MaxLength > 0 {
-		s[5] = fmt.Sprint(r.MaxLength)
+	// Min/Max comparison.
+	if r.InferredType == CodeType {
+		// Numeric range of code is meaningless, such as "1" for "01".
+		s = append(s, "", "")
+	} else if r.PIIType != "" {
+		// Range of personal data is redacted.
+		s = append(s, "", "")
+	} else if r.useTime() {
+		s = append(s, r.MinTime.Format("2006-01-02 15:04:05"), r.MaxTime.Format("2006-01-02 15:04:05"))
+	} else if r.TypeFloat > 0 {
+		s = append(s, fmt.Sprintf("%.4f", *r.Minimum), fmt.Sprintf("%.4f", *r.Maximum))
+	} else if r.TypeInt > 0 {
+		s = append(s, fmt.Sprint(*r.Minimum), fmt.Sprint(*r.Maximum))
 	} else {
-		s[5] = ""
+		s = append(s, "", "")
 	}
-	if r.TypeInt > 0 {
-		s[6] = fmt.Sprint(r.TypeInt)
+	if r.TypeBool > 0 {
+		s = append(s, fmt.Sprint(*r.BoolTrue), fmt.Sprint(*r.BoolFalse))
 	} else {
-		s[6] = ""
+		s = append(s, "", "")
 	}
-	if r.TypeFloat > 0 {
-		s[7] = fmt.Sprint(r.TypeFloat)
+	if r.Mean != nil {
+		s = append(s, fmt.Sprintf("%.4f", *r.Mean), fmt.Sprintf("%.4f", *r.Variance),
+			fmt.Sprintf("%.4f", *r.StdDev), fmt.Sprintf("%.4f", *r.Sum))
 	} else {
-		s[7] = ""
+		s = append(s, "", "", "", "")
 	}
-	if r.TypeBool > 0 {
-		s[8] = fmt.Sprint(r.TypeBool)
+	if r.useTime() && r.MedianTime != nil {
+		s = append(s, r.MedianTime.Format("2006-01-02 15:04:05"))
+	} else if !r.useTime() && r.Median != nil {
+		s = append(s, fmt.Sprintf("%.4f", *r.Median))
 	} else {
-		s[8] = ""
+		s = append(s, "")
 	}
-	if r.TypeTime > 0 {
-		s[9] = fmt.Sprint(r.TypeTime)
+	s = append(s, r.formatQuantiles())
+	if r.Distinct > 0 {
+		s = append(s, fmt.Sprint(r.Distinct), fmt.Sprintf("%.4f", r.DistinctRatio))
 	} else {
-		s[9] = ""
+		s = append(s, "", "")
 	}
-	// Min/Max comparison.
-	if r.TypeTime > r.TypeFloat {
-		s[10] = r.MinTime.Format("2006-01-02 15:04:05")
-		s[11] = r.MaxTime.Format("2006-01-02 15:04:05")
-	} else if r.TypeFloat > 0 {
-		s[10] = fmt.Sprintf("%.4f", *r.Minimum)
-		s[11] = fmt.Sprintf("%.4f", *r.Maximum)
-	} else if r.TypeInt > 0 {
-		s[10] = fmt.Sprint(*r.Minimum)
-		s[11] = fmt.Sprint(*r.Maximum)
+	if r.CandidateKey {
+		s = append(s, "true")
 	} else {
-		s[10] = ""
-		s[11] = ""
+		s = append(s, "")
 	}
-	if r.TypeBool > 0 {
-		s[12] = fmt.Sprint(*r.BoolTrue)
-		s[13] = fmt.Sprint(*r.BoolFalse)
+	c := r.Characters
+	s = append(s, formatCount(c.FullWidth), formatCount(c.HalfWidthKana),
+		formatCount(c.Hiragana), formatCount(c.Kanji), formatCount(c.ASCII),
+		formatCount(c.Digits), formatCount(c.Control), formatCount(c.Replacement))
+	s = append(s, r.TimeLayout)
+	if r.MixedTimeLayouts {
+		s = append(s, "true")
 	} else {
-		s[12] = ""
-		s[13] = ""
+		s = append(s, "")
 	}
+	s = append(s, formatCount(r.TypeWareki))
+	if r.EpochUnit != "" && r.MinTime != nil {
+		s = append(s, r.EpochUnit, r.MinTime.Format("2006-01-02 15:04:05"), r.MaxTime.Format("2006-01-02 15:04:05"))
+	} else {
+		s = append(s, r.EpochUnit, "", "")
+	}
+	s = append(s, formatCount(r.TypeCode))
+	s = append(s, formatCount(r.TypeFormatted), r.NumberUnit)
+	if r.SemanticType != "" {
+		s = append(s, r.SemanticType, fmt.Sprintf("%.4f", r.SemanticRatio))
+	} else {
+		s = append(s, "", "")
+	}
+	if r.PIIRatio > 0 {
+		s = append(s, r.PIIType, fmt.Sprintf("%.4f", r.PIIRatio), r.PIIExample)
+	} else {
+		s = append(s, "", "", "")
+	}
+	if len(r.TopPatterns) > 0 {
+		s = append(s, r.formatPatterns(), fmt.Sprintf("%.4f", r.PatternCoverage))
+	} else {
+		s = append(s, "", "")
+	}
+	s = append(s, formatCount(r.OutliersIQR), formatCount(r.OutliersZScore), r.formatOutliers())
 	return s
 }
 
+// ideographicSpace is a full-width space, which is often used to pad
+// values in Japanese documents.
+const ideographicSpace = '\u3000'
+
+// padding returns leading and trailing white spaces of s.
+func padding(s string) string {
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) == 0 {
+		return s
+	}
+	i := strings.Index(s, trimmed)
+	return s[:i] + s[i+len(trimmed):]
+}
+
+// formatCount returns string of positive count, otherwise empty string.
+func formatCount(n int) string {
+	if n > 0 {
+		return fmt.Sprint(n)
+	}
+	return ""
+}
+
+// useTime reports whether time values represent the range of the field
+// rather than numeric values.
+func (r *ReportField) useTime() bool {
+	switch r.InferredType {
+	case DateType, DateTimeType:
+		return true
+	case IntegerType, DecimalType:
+		return false
+	}
+	return r.TypeTime > r.TypeFloat
+}
+
+// formatQuantiles returns estimated quantiles as one string.
+func (r *ReportField) formatQuantiles() string {
+	quantiles := make([]string, 0, len(r.Quantiles))
+	for _, q := range r.Quantiles {
+		if r.useTime() && q.Time != nil {
+			quantiles = append(quantiles, fmt.Sprintf("p%v=%s", q.Percentile, q.Time.Format("2006-01-02 15:04:05")))
+		} else if !r.useTime() && q.Value != nil {
+			quantiles = append(quantiles, fmt.Sprintf("p%v=%.4f", q.Percentile, *q.Value))
+		}
+	}
+	return strings.Join(quantiles, " ")
+}
+
 func (r *Report) header(record []string) error {
 	if len(record) == 0 {
 		return fmt.Errorf("header record has no elements")
@@ -136,7 +346,48 @@ func (r *Report) header(record []string) error {
 	return nil
 }
 
+// profileOption returns the option to profile values, or default one.
+func (r *Report) profileOption() *ProfileOption {
+	if r.option == nil {
+		return &defaultProfileOption
+	}
+	return r.option
+}
+
+// numberRecognizer returns the recognizer of formatted numbers in the
+// locale of the option, or nil if the locale is not given.
+func (r *Report) numberRecognizer() numberRecognizer {
+	if r.number == nil {
+		if locale := r.profileOption().NumberLocale; locale != "" {
+			r.number, _ = lookupNumberRecognizer(locale)
+		}
+	}
+	return r.number
+}
+
+// timeParser returns the parser of time values along with the option.
+func (r *Report) timeParser() *timeParser {
+	if r.parser == nil {
+		r.parser = r.profileOption().timeParser()
+	}
+	return r.parser
+}
+
+// parseRecord parses record next to the last one, whose line number is
+// counted by records.
 func (r *Report) parseRecord(record []string) (nullCount int) {
+	line := r.Records + 1
+	if r.HasHeader {
+		line++
+	}
+	return r.parseRecordAt(record, line)
+}
+
+// parseRecordAt parses record which starts at the line of input.
+func (r *Report) parseRecordAt(record []string, line int) (nullCount int) {
+	option := r.profileOption()
+	parser := r.timeParser()
+	number := r.numberRecognizer()
 	r.Records++
 	size := len(record)
 	if size > len(r.Fields) {
@@ -144,6 +395,7 @@ func (r *Report) parseRecord(record []string) (nullCount int) {
 			f := new(ReportField)
 			f.Name = fmt.Sprintf("Column%03d", i+1)
 			f.Blank = r.Records - 1 // suppose all cells are blank until up to here.
+			f.Empty = f.Blank
 			r.Fields = append(r.Fields, f)
 		}
 	}
@@ -153,8 +405,41 @@ func (r *Report) parseRecord(record []string) (nullCount int) {
 		if len(val) == 0 {
 			nullCount++
 			f.Blank++
+			if len(record[i]) == 0 {
+				f.Empty++
+			} else {
+				f.WhiteSpace++
+				if strings.ContainsRune(record[i], ideographicSpace) {
+					f.IdeographicSpace++
+				}
+			}
 			continue
 		}
+		if r.dialect != nil && r.dialect.IsNull(val) {
+			nullCount++
+			f.Blank++
+			f.NullToken++
+			continue
+		}
+		if len(val) < len(record[i]) {
+			f.Padded++
+			if strings.ContainsRune(padding(record[i]), ideographicSpace) {
+				f.IdeographicSpace++
+			}
+		}
+		f.distinct.add(val, option.DistinctThreshold)
+		if option.TopValues > 0 {
+			if f.topValues == nil {
+				f.topValues = newSpaceSaving(option.TopValues * topValuesFactor)
+			}
+			f.topValues.add(val)
+		}
+		if option.TopPatterns > 0 {
+			if f.patterns == nil {
+				f.patterns = newSpaceSaving(option.TopPatterns * topValuesFactor)
+			}
+			f.patterns.add(shape(val))
+		}
 		stringLength := utf8.RuneCountInString(val)
 		if f.MinLength == 0 || f.MinLength > stringLength {
 			f.MinLength = stringLength
@@ -162,10 +447,24 @@ func (r *Report) parseRecord(record []string) (nullCount int) {
 		if f.MaxLength < stringLength {
 			f.MaxLength = stringLength
 		}
-		if valid.IsFullWidth(val) {
-			f.fullWidth++
+		f.addLength(val, stringLength, option.LengthBuckets)
+		f.Characters.add(val)
+		f.Semantics.add(val)
+		if option.DetectPII {
+			if f.PII == nil {
+				f.PII = new(PIIProfile)
+			}
+			f.PII.add(val)
+		}
+		f.addCode(val)
+		formatted := false
+		if number != nil {
+			if v, unit, integral, ok := number.parse(val); ok {
+				f.addFormattedNumber(v, unit, integral, line)
+				formatted = true
+			}
 		}
-		if valInt, err := strconv.Atoi(val); err == nil {
+		if valInt, err := strconv.Atoi(val); err == nil && !formatted {
 			v := float64(valInt)
 			if f.Minimum == nil {
 				f.Minimum = new(float64)
@@ -183,7 +482,8 @@ func (r *Report) parseRecord(record []string) (nullCount int) {
 			}
 			f.TypeInt++
 		}
-		if valFloat, err := strconv.ParseFloat(val, 64); err == nil {
+		// ParseFloat accepts "NaN" and "Inf", which are not numbers in tabular data.
+		if valFloat, err := strconv.ParseFloat(val, 64); err == nil && !formatted && !math.IsNaN(valFloat) && !math.IsInf(valFloat, 0) {
 			if f.Minimum == nil {
 				f.Minimum = new(float64)
 				*f.Minimum = valFloat
@@ -198,6 +498,12 @@ func (r *Report) parseRecord(record []string) (nullCount int) {
 			if valFloat > *f.Maximum {
 				*f.Maximum = valFloat
 			}
+			f.stats.add(valFloat)
+			if f.numDigest == nil {
+				f.numDigest = newTDigest(digestCompression)
+			}
+			f.numDigest.add(valFloat)
+			f.extremes.add(valFloat, line)
 			f.TypeFloat++
 		}
 		if valBool, err := strconv.ParseBool(val); err == nil {
@@ -212,7 +518,7 @@ func (r *Report) parseRecord(record []string) (nullCount int) {
 			}
 			f.TypeBool++
 		}
-		if valTime, err := parseDateTime(val); err == nil {
+		if valTime, layout, err := parser.parse(val); err == nil {
 			if f.TypeTime == 0 {
 				f.MinTime = new(time.Time)
 				f.MaxTime = new(time.Time)
@@ -225,20 +531,166 @@ func (r *Report) parseRecord(record []string) (nullCount int) {
 			if valTime.After(*f.MaxTime) {
 				*f.MaxTime = valTime
 			}
+			if f.timeDigest == nil {
+				f.timeDigest = newTDigest(digestCompression)
+			}
+			f.timeDigest.add(float64(valTime.UnixNano()))
+			if observed, ambiguous := observedLayout(layout, val); ambiguous {
+				if f.ambiguousLayouts == nil {
+					f.ambiguousLayouts = make(map[string]int)
+				}
+				f.ambiguousLayouts[layout]++
+			} else {
+				if f.TimeLayouts == nil {
+					f.TimeLayouts = make(map[string]int)
+					f.layoutBases = make(map[string]string)
+				}
+				f.TimeLayouts[observed]++
+				f.layoutBases[observed] = layout
+			}
+			if layout == warekiLayout {
+				f.TypeWareki++
+			}
+			if !layoutHasClock(layout) {
+				f.typeDate++
+			}
 			f.TypeTime++
 		}
 	}
 	return nullCount
 }
 
-func newReport(f File) *Report {
+// summarize fills statistics which are available after parsing all records.
+func (r *Report) summarize() {
+	option := r.profileOption()
+	for _, f := range r.Fields {
+		f.summarizeDistinct(r.Records)
+		f.inferType(r.Records, option.TypeThreshold)
+		f.summarizeTimeLayout()
+		f.summarizeNumberUnit()
+		f.summarizeSemantic(r.Records)
+		f.summarizePII(r.Records, option.PIIThreshold)
+		if option.DetectEpoch {
+			f.summarizeEpoch(r.timeParser().location)
+		}
+		if f.topValues != nil {
+			f.TopValues = f.topValues.top(option.TopValues)
+		}
+		f.summarizePatterns(r.Records, option.TopPatterns)
+		f.summarizeLength(r.Records, option.LengthBuckets)
+		f.summarizeHistogram(option.HistogramBins, r.timeParser().location)
+		f.summarizeOutliers(option.OutlierIQR, option.OutlierZScore, option.OutlierExamples)
+		if f.stats.n > 0 {
+			mean := f.stats.Mean()
+			variance := f.stats.Variance()
+			stddev := f.stats.StdDev()
+			sum := f.stats.Sum()
+			f.Mean = &mean
+			f.Variance = &variance
+			f.StdDev = &stddev
+			f.Sum = &sum
+		}
+		if f.numDigest == nil && f.timeDigest == nil {
+			continue
+		}
+		f.Quantiles = make([]Quantile, len(option.Percentiles))
+		for i, p := range option.Percentiles {
+			f.Quantiles[i].Percentile = p
+		}
+		if f.numDigest != nil {
+			median := f.numDigest.quantile(0.5)
+			f.Median = &median
+			for i, p := range option.Percentiles {
+				v := f.numDigest.quantile(p / 100)
+				f.Quantiles[i].Value = &v
+			}
+		}
+		if f.timeDigest != nil {
+			location := r.timeParser().location
+			median := digestTime(f.timeDigest.quantile(0.5), location)
+			f.MedianTime = &median
+			for i, p := range option.Percentiles {
+				v := digestTime(f.timeDigest.quantile(p/100), location)
+				f.Quantiles[i].Time = &v
+			}
+		}
+	}
+	for _, f := range r.Fields {
+		if f.InferredType == CodeType {
+			f.dropNumbers()
+		}
+		if f.PIIType != "" {
+			f.redactValues()
+		}
+	}
+}
+
+// summarizeDistinct fills cardinality of the field, and flags it as
+// candidate key when all records have distinct values.
+// Since estimated cardinality has error, the field is flagged if the
+// estimate is within twice of standard error of the sketch.
+func (f *ReportField) summarizeDistinct(records int) {
+	distinct, exact := f.distinct.count()
+	filled := records - f.Blank
+	if distinct > filled {
+		distinct = filled
+	}
+	f.Distinct = distinct
+	f.DistinctApprox = !exact
+	if filled > 0 {
+		f.DistinctRatio = float64(distinct) / float64(filled)
+	}
+	if f.Blank > 0 || records == 0 {
+		f.CandidateKey = false
+	} else if exact {
+		f.CandidateKey = distinct == records
+	} else {
+		f.CandidateKey = float64(records-distinct) <= 2*f.distinct.sketch.stdError()*float64(records)
+	}
+}
+
+// summarizeTimeLayout sets the most used layout of time values,
+// and flags the field if different layouts are mixed.
+// Values whose padding is not observed, such as "2016/12/25", are counted
+// as the most used layout matching them, or zero padded one.
+func (f *ReportField) summarizeTimeLayout() {
+	for layout, count := range f.ambiguousLayouts {
+		observed, max := "", 0
+		for o, c := range f.TimeLayouts {
+			if f.layoutBases[o] == layout && (c > max || (c == max && o < observed)) {
+				observed, max = o, c
+			}
+		}
+		if observed == "" {
+			observed = paddedLayout(layout)
+		}
+		if f.TimeLayouts == nil {
+			f.TimeLayouts = make(map[string]int)
+		}
+		f.TimeLayouts[observed] += count
+	}
+	f.ambiguousLayouts = nil
+	max := 0
+	for layout, count := range f.TimeLayouts {
+		if count > max || (count == max && layout < f.TimeLayout) {
+			f.TimeLayout = layout
+			max = count
+		}
+	}
+	f.MixedTimeLayouts = len(f.TimeLayouts) > 1
+}
+
+// digestTime converts value in time digest to time in the location.
+func digestTime(v float64, location *time.Location) time.Time {
+	return time.Unix(0, int64(v)).In(location)
+}
+
+func newReport(f File, option *ProfileOption) *Report {
 	r := new(Report)
+	r.option = option
 	if f.path != "" {
 		r.Path = f.path
 		r.Filename = f.Name()
-		if md5hex, err := f.Checksum(); err == nil {
-			r.MD5hex = md5hex
-		}
 	}
 	return r
 }
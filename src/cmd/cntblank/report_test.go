@@ -1,15 +1,19 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"csvhelper"
 )
 
 func TestNewReport_Empty(t *testing.T) {
 	a := assert.New(t)
-	r := newReport(File{})
+	r := newReport(File{}, nil)
 	a.Empty(r.Path, "Path sould be empty")
 	a.Empty(r.Filename, "Filename should be empty")
 	a.Empty(r.MD5hex, "MD5hex should be empty")
@@ -20,7 +24,7 @@ func TestNewReport_Empty(t *testing.T) {
 
 func TestNewReport_Filename(t *testing.T) {
 	a := assert.New(t)
-	r := newReport(File{path: "/path/to/file"})
+	r := newReport(File{path: "/path/to/file"}, nil)
 	a.Equal("/path/to/file", r.Path, "Path is different")
 	a.Equal("file", r.Filename, "Filename is different")
 	a.Empty(r.MD5hex, "MD5hex should be empty")
@@ -65,7 +69,7 @@ func TestReport(t *testing.T) {
 	} {
 		f := report.Fields[i]
 		r := f.format(report.Records)
-		if len(r) != 14 {
+		if len(r) != 57 {
 			t.Errorf("#%d field formatter returns invalid result, which has %d elements.", i+1, len(r))
 		}
 		if r[1] != tc.columnName {
@@ -165,8 +169,8 @@ func TestReportTypeDetection(t *testing.T) {
 		if f.TypeTime != tc.timeType {
 			t.Errorf("#%d fail to count time type: actual=%d, expected=%d", i+1, f.TypeTime, tc.timeType)
 		}
-		if f.fullWidth != tc.fullWidth {
-			t.Errorf("#%d fail to count full width: actual=%d, expected=%d", i+1, f.fullWidth, tc.fullWidth)
+		if f.Characters.FullWidth != tc.fullWidth {
+			t.Errorf("#%d fail to count full width: actual=%d, expected=%d", i+1, f.Characters.FullWidth, tc.fullWidth)
 		}
 	}
 }
@@ -187,10 +191,27 @@ var formatTests = []struct {
 		Expected: []string{
 			"", "Column001", // seq, Name
 			"98", "0.9800", // #Blank, %Blank
+			"", "", "", // #Empty, #WhiteSpace, #NullToken
+			"", "", // #Padded, #IdeographicSpace
 			"10", "10", // MinLength, MaxLength
 			"", "", "", "2", // #Int, #Float, #Bool, #Time
+			"", "", // Type, %Type
 			"2015-10-29 00:00:00", "2015-11-05 00:00:00", // Minimum, Maximum
 			"", "", // #True, #False
+			"", "", "", "", // Mean, Variance, StdDev, Sum
+			"", "", // Median, Quantiles
+			"", "", "", // #Distinct, %Distinct, Key
+			"", "", "", "", // #FullWidth, #HalfWidthKana, #Hiragana, #Kanji
+			"", "", "", "", // #ASCII, #Digits, #Control, #Replacement
+			"", "", // TimeLayout, MixedLayout
+			"",         // #Wareki
+			"", "", "", // Epoch, EpochMin, EpochMax
+			"",     // #Code
+			"", "", // #Formatted, Unit
+			"", "", // Semantic, %Semantic
+			"", "", "", // PII, %PII, PIIExample
+			"", "", // Patterns, %Pattern
+			"", "", "", // #OutlierIQR, #OutlierZ, Outliers
 		},
 	},
 	{
@@ -200,14 +221,440 @@ var formatTests = []struct {
 		Expected: []string{
 			"", "Column002", // seq, Name
 			"50", "0.5000", // #Blank, %Blank
+			"", "", "", // #Empty, #WhiteSpace, #NullToken
+			"", "", // #Padded, #IdeographicSpace
 			"3", "12", // MinLength, MaxLength
 			"", "50", "", "", // #Int, #Float, #Bool, #Time
+			"", "", // Type, %Type
 			"1.1000", "2.2000", // Minimum, Maximum
 			"", "", // #True, #False
+			"", "", "", "", // Mean, Variance, StdDev, Sum
+			"", "", // Median, Quantiles
+			"", "", "", // #Distinct, %Distinct, Key
+			"", "", "", "", // #FullWidth, #HalfWidthKana, #Hiragana, #Kanji
+			"", "", "", "", // #ASCII, #Digits, #Control, #Replacement
+			"", "", // TimeLayout, MixedLayout
+			"",         // #Wareki
+			"", "", "", // Epoch, EpochMin, EpochMax
+			"",     // #Code
+			"", "", // #Formatted, Unit
+			"", "", // Semantic, %Semantic
+			"", "", "", // PII, %PII, PIIExample
+			"", "", // Patterns, %Pattern
+			"", "", "", // #OutlierIQR, #OutlierZ, Outliers
 		},
 	},
 }
 
+func TestReportStatistics(t *testing.T) {
+	a := assert.New(t)
+	report := new(Report)
+	for _, s := range [][]string{
+		{"1", "a", "2.5"},
+		{"2", "b", ""},
+		{"3", "c", "-0.5"},
+		{"4", "", "1e2"},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.InDelta(2.5, *f.Mean, 1e-9)
+	a.InDelta(5.0/3.0, *f.Variance, 1e-9)
+	a.InDelta(1.2909944, *f.StdDev, 1e-6)
+	a.InDelta(10.0, *f.Sum, 1e-9)
+	r := f.format(report.Records)
+	a.Equal([]string{"2.5000", "1.6667", "1.2910", "10.0000"}, r[21:25])
+	f = report.Fields[1]
+	a.Nil(f.Mean, "non-numeric column should not have mean")
+	a.Nil(f.Sum, "non-numeric column should not have sum")
+	a.Equal([]string{"", "", "", ""}, f.format(report.Records)[21:25])
+	f = report.Fields[2]
+	a.InDelta(34.0, *f.Mean, 1e-9)
+	a.InDelta(102.0, *f.Sum, 1e-9)
+}
+
+func TestReportNonFinite(t *testing.T) {
+	a := assert.New(t)
+	report := new(Report)
+	for _, s := range []string{"Nan", "Inf", "Alice", "Bob", "-Infinity", "1"} {
+		report.parseRecord([]string{s})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(1, f.TypeFloat, "NaN and Inf should not be numbers")
+	a.Equal(1.0, *f.Mean)
+	a.Equal(1.0, *f.Minimum)
+	a.Equal(1.0, *f.Maximum)
+	_, err := json.Marshal(report)
+	a.Nil(err)
+}
+
+func TestReportQuantiles(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.Percentiles = []float64{25, 75}
+	report := newReport(File{}, option)
+	for i := 1; i <= 9; i++ {
+		report.parseRecord([]string{fmt.Sprint(i * 10), fmt.Sprintf("2016-01-%02d", i), "x"})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.InDelta(50.0, *f.Median, 1e-9)
+	a.Equal(2, len(f.Quantiles))
+	a.Equal(25.0, f.Quantiles[0].Percentile)
+	a.InDelta(27.5, *f.Quantiles[0].Value, 1e-9)
+	a.InDelta(72.5, *f.Quantiles[1].Value, 1e-9)
+	a.Nil(f.Quantiles[0].Time)
+	r := f.format(report.Records)
+	a.Equal("50.0000", r[25])
+	a.Equal("p25=27.5000 p75=72.5000", r[26])
+	f = report.Fields[1]
+	a.Equal("2016-01-05 00:00:00", f.MedianTime.Format("2006-01-02 15:04:05"))
+	r = f.format(report.Records)
+	a.Equal("2016-01-05 00:00:00", r[25])
+	a.Equal("p25=2016-01-02 18:00:00 p75=2016-01-07 06:00:00", r[26])
+	f = report.Fields[2]
+	a.Nil(f.Median)
+	a.Nil(f.Quantiles)
+}
+
+func TestReportDistinct(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.DistinctThreshold = 3
+	report := newReport(File{}, option)
+	for _, s := range [][]string{
+		{"1", "a", "x", "p"},
+		{"2", "a", "y", "q"},
+		{"3", "b", "", "r"},
+		{"4", "b", "z", "p"},
+		{"5", "c", "w", "t"},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	for i, tc := range []struct {
+		distinct int
+		ratio    float64
+		approx   bool
+		key      bool
+	}{
+		{5, 1.0, true, true},
+		{3, 0.6, false, false},
+		{4, 1.0, true, false},
+		{4, 0.8, true, false},
+	} {
+		f := report.Fields[i]
+		a.Equal(tc.distinct, f.Distinct, "#%d distinct count", i+1)
+		a.InDelta(tc.ratio, f.DistinctRatio, 1e-9, "#%d distinct ratio", i+1)
+		a.Equal(tc.approx, f.DistinctApprox, "#%d approximation", i+1)
+		a.Equal(tc.key, f.CandidateKey, "#%d candidate key", i+1)
+	}
+	r := report.Fields[0].format(report.Records)
+	a.Equal([]string{"5", "1.0000", "true"}, r[27:30])
+	r = report.Fields[1].format(report.Records)
+	a.Equal([]string{"3", "0.6000", ""}, r[27:30])
+}
+
+func TestReportTopValues(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.TopValues = 2
+	report := newReport(File{}, option)
+	for _, s := range [][]string{
+		{"-", "1"},
+		{"Tokyo", "2"},
+		{"-", "3"},
+		{"不明", ""},
+		{"-", "5"},
+		{" 不明 ", "6"},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	a.Equal([]ValueCount{{"-", 3, 0}, {"不明", 2, 0}}, report.Fields[0].TopValues)
+	a.Equal(2, len(report.Fields[1].TopValues))
+
+	option.TopValues = 0
+	report = newReport(File{}, option)
+	report.parseRecord([]string{"a"})
+	report.summarize()
+	a.Nil(report.Fields[0].TopValues, "top values should be disabled")
+}
+
+func TestReportNullToken(t *testing.T) {
+	a := assert.New(t)
+	report := newReport(File{}, nil)
+	report.dialect = &csvhelper.FileDialect{
+		NullValues:     []string{"NULL", `\N`},
+		NullIgnoreCase: true,
+	}
+	for i, tc := range []struct {
+		record    []string
+		nullCount int
+	}{
+		{[]string{"1", "NULL"}, 1},
+		{[]string{"null", ""}, 2},
+		{[]string{" \\N ", "NULLABLE"}, 1},
+		{[]string{"4"}, 0},
+	} {
+		a.Equal(tc.nullCount, report.parseRecord(tc.record), "null count of #%d", i+1)
+	}
+	for i, tc := range []struct {
+		blank     int
+		empty     int
+		nullToken int
+	}{
+		{2, 0, 2},
+		{2, 1, 1},
+	} {
+		f := report.Fields[i]
+		a.Equal(tc.blank, f.Blank, "blank count of #%d", i+1)
+		a.Equal(tc.empty, f.Empty, "empty count of #%d", i+1)
+		a.Equal(tc.nullToken, f.NullToken, "null token count of #%d", i+1)
+	}
+	a.Equal(2, report.Fields[0].TypeInt, "null tokens should not be parsed")
+	r := report.Fields[1].format(report.Records)
+	a.Equal([]string{"2", "0.5000", "1", "", "1"}, r[2:7])
+}
+
+func TestReportWhiteSpace(t *testing.T) {
+	a := assert.New(t)
+	report := new(Report)
+	for _, s := range [][]string{
+		{"", "a", "a"},
+		{" ", " a", "\u3000a\u3000"},
+		{"\u3000", "a\t", "a\u3000b"},
+		{"\t\u3000 ", "a", "\u3000"},
+	} {
+		report.parseRecord(s)
+	}
+	for i, tc := range []struct {
+		blank            int
+		empty            int
+		whiteSpace       int
+		padded           int
+		ideographicSpace int
+	}{
+		{4, 1, 3, 0, 2},
+		{0, 0, 0, 2, 0},
+		{1, 0, 1, 1, 2},
+	} {
+		f := report.Fields[i]
+		a.Equal(tc.blank, f.Blank, "blank count of #%d", i+1)
+		a.Equal(tc.empty, f.Empty, "empty count of #%d", i+1)
+		a.Equal(tc.whiteSpace, f.WhiteSpace, "white space count of #%d", i+1)
+		a.Equal(tc.padded, f.Padded, "padded count of #%d", i+1)
+		a.Equal(tc.ideographicSpace, f.IdeographicSpace, "ideographic space count of #%d", i+1)
+	}
+	a.Equal(1, report.Fields[2].MinLength, "padding should not be counted in length")
+	a.Equal(3, report.Fields[2].MaxLength, "inner space should be counted in length")
+}
+
+func TestPadding(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("", padding("abc"))
+	a.Equal("  ", padding(" abc "))
+	a.Equal("\u3000\t", padding("\u3000a b\t"))
+	a.Equal(" \u3000", padding(" \u3000"))
+}
+
+func TestReportTimeLayout(t *testing.T) {
+	a := assert.New(t)
+	report := new(Report)
+	for _, s := range [][]string{
+		{"2016/1/2", "2016-01-02"},
+		{"2016-01-03", "2016-01-03"},
+		{"2016/1/4", "x"},
+		{"2016/01/05", ""},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(map[string]int{"2006/1/2": 2, "2006/01/02": 1, "2006-01-02": 1}, f.TimeLayouts)
+	a.Equal("2006/1/2", f.TimeLayout)
+	a.True(f.MixedTimeLayouts)
+	a.Equal([]string{"2006/1/2", "true"}, f.format(report.Records)[38:40])
+	f = report.Fields[1]
+	a.Equal("2006-01-02", f.TimeLayout)
+	a.False(f.MixedTimeLayouts)
+	a.Equal([]string{"2006-01-02", ""}, f.format(report.Records)[38:40])
+
+	// Values without padding evidence follow the others.
+	report = new(Report)
+	for _, s := range [][]string{
+		{"2016/1/2", "2016/01/02"},
+		{"2016/12/25", "2016/1/3"},
+		{"2016/10/10", "2016/12/25"},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	a.Equal(map[string]int{"2006/1/2": 3}, report.Fields[0].TimeLayouts)
+	a.False(report.Fields[0].MixedTimeLayouts)
+	a.Equal(map[string]int{"2006/01/02": 2, "2006/1/2": 1}, report.Fields[1].TimeLayouts)
+	a.True(report.Fields[1].MixedTimeLayouts)
+	report = new(Report)
+	report.parseRecord([]string{"2016/12/25"})
+	report.summarize()
+	a.Equal(map[string]int{"2006/01/02": 1}, report.Fields[0].TimeLayouts)
+}
+
+func TestReportWareki(t *testing.T) {
+	a := assert.New(t)
+	report := new(Report)
+	for _, s := range []string{"令和2年4月1日", "H31.4.30", "平成元年1月8日", "2019-05-01"} {
+		report.parseRecord([]string{s})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(4, f.TypeTime)
+	a.Equal(3, f.TypeWareki)
+	a.Equal("1989-01-08", f.MinTime.Format("2006-01-02"))
+	a.Equal("2020-04-01", f.MaxTime.Format("2006-01-02"))
+	a.Equal(DateType, f.InferredType)
+	a.Equal(warekiLayout, f.TimeLayout)
+	a.True(f.MixedTimeLayouts)
+	a.Equal("3", f.format(report.Records)[40])
+}
+
+func TestReportCode(t *testing.T) {
+	a := assert.New(t)
+	report := new(Report)
+	for _, s := range []string{"01", "02", "13", "47"} {
+		report.parseRecord([]string{s})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(2, f.TypeCode)
+	a.Equal(4, f.TypeInt)
+	a.Equal(CodeType, f.InferredType)
+	r := f.format(report.Records)
+	a.Equal([]string{"code", "1.0000"}, r[15:17])
+	a.Equal([]string{"", ""}, r[17:19], "numeric range of code should be empty")
+	a.Equal("2", r[44])
+	b, err := json.Marshal(f)
+	a.Nil(err)
+	for _, key := range []string{`"minimum"`, `"maximum"`, `"mean"`, `"median"`, `"quantiles"`, `"histogram"`} {
+		a.NotContains(string(b), key)
+	}
+	a.Contains(string(b), `"inferredType":"code"`)
+}
+
+func TestReportFormattedNumber(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.NumberLocale = "ja-JP"
+	report := newReport(File{}, option)
+	for _, s := range [][]string{
+		{"¥1,000", "12.5%"},
+		{"(300)", "5%"},
+		{"¥2,500", "7"},
+		{"１２３", "x"},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(4, f.TypeInt)
+	a.Equal(4, f.TypeFloat)
+	a.Equal(4, f.TypeFormatted)
+	a.Equal(IntegerType, f.InferredType)
+	a.Equal(-300.0, *f.Minimum)
+	a.Equal(2500.0, *f.Maximum)
+	a.Equal(map[string]int{"¥": 2}, f.NumberUnits)
+	r := f.format(report.Records)
+	a.Equal([]string{"4", "¥"}, r[45:47])
+	f = report.Fields[1]
+	a.Equal(2, f.TypeInt)
+	a.Equal(3, f.TypeFloat)
+	a.Equal(2, f.TypeFormatted)
+	a.Equal("%", f.NumberUnit)
+	a.Equal(12.5, *f.Maximum)
+
+	report = new(Report)
+	report.parseRecord([]string{"1,234"})
+	report.summarize()
+	a.Equal(0, report.Fields[0].TypeFloat, "formatted numbers should be opt-in")
+}
+
+func TestReportEpoch(t *testing.T) {
+	a := assert.New(t)
+	option := NewProfileOption()
+	option.DetectEpoch = true
+	report := newReport(File{}, option)
+	for _, s := range [][]string{
+		{"1451606400", "1451606400000", "42370", "1"},
+		{"1483228799", "1451606400999", "42370.5", "1451606400"},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	r := report.Fields[0].format(report.Records)
+	a.Equal("1451606400.0000", r[17])
+	a.Equal([]string{"s", "2016-01-01 00:00:00", "2016-12-31 23:59:59"}, r[41:44])
+	r = report.Fields[1].format(report.Records)
+	a.Equal([]string{"ms", "2016-01-01 00:00:00", "2016-01-01 00:00:00"}, r[41:44])
+	r = report.Fields[2].format(report.Records)
+	a.Equal([]string{"excel?", "", ""}, r[41:44], "serial date requires date-like name")
+	f := report.Fields[3]
+	a.Equal("", f.EpochUnit)
+	a.Nil(f.MinTime)
+
+	report = new(Report)
+	report.parseRecord([]string{"1451606400"})
+	report.summarize()
+	a.Equal("", report.Fields[0].EpochUnit, "detection should be opt-in")
+
+	report = newReport(File{}, option)
+	report.header([]string{"price", "order_date", "日付"})
+	for _, s := range [][]string{
+		{"42370", "42370", "42370"},
+		{"43000", "42735", "42735"},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	f = report.Fields[0]
+	a.Equal(excelCandidate, f.EpochUnit)
+	a.Nil(f.MinTime)
+	r = f.format(report.Records)
+	a.Equal([]string{"excel?", "", ""}, r[41:44])
+	for _, f := range report.Fields[1:] {
+		a.Equal(excelSerial, f.EpochUnit, f.Name)
+		a.Equal("2016-01-01", f.MinTime.Format("2006-01-02"), f.Name)
+	}
+}
+
+func TestReportTimeLocation(t *testing.T) {
+	a := assert.New(t)
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	option := NewProfileOption()
+	option.TimeLayouts = []string{"2006-01-02T15:04:05.000-0700"}
+	option.Location = jst
+	report := newReport(File{}, option)
+	for _, s := range []string{
+		"2016-01-02T00:00:00.000+0900",
+		"2016-01-01T16:00:00.000+0000",
+		"2016-01-03 09:00:00",
+	} {
+		report.parseRecord([]string{s})
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(3, f.TypeTime)
+	r := f.format(report.Records)
+	a.Equal("2016-01-02 00:00:00", r[17], "minimum should be in the location")
+	a.Equal("2016-01-03 09:00:00", r[18], "maximum should be in the location")
+	a.Equal("2016-01-02 01:00:00", r[25])
+	a.Equal(jst, f.MedianTime.Location())
+}
+
 func TestReportFieldFormat(t *testing.T) {
 	for n, tt := range formatTests {
 		field := new(ReportField)
@@ -245,22 +692,65 @@ func TestReportFieldFormat(t *testing.T) {
 func TestReportFieldHeader(t *testing.T) {
 	a := assert.New(t)
 	header := ReportField{}.header()
-	a.Equal(14, len(header))
+	a.Equal(57, len(header))
 	for i, s := range []string{
 		"seq",
 		"Name",
 		"#Blank",
 		"%Blank",
+		"#Empty",
+		"#WhiteSpace",
+		"#NullToken",
+		"#Padded",
+		"#IdeographicSpace",
 		"MinLength",
 		"MaxLength",
 		"#Int",
 		"#Float",
 		"#Bool",
 		"#Time",
+		"Type",
+		"%Type",
 		"Minimum",
 		"Maximum",
 		"#True",
 		"#False",
+		"Mean",
+		"Variance",
+		"StdDev",
+		"Sum",
+		"Median",
+		"Quantiles",
+		"#Distinct",
+		"%Distinct",
+		"Key",
+		"#FullWidth",
+		"#HalfWidthKana",
+		"#Hiragana",
+		"#Kanji",
+		"#ASCII",
+		"#Digits",
+		"#Control",
+		"#Replacement",
+		"TimeLayout",
+		"MixedLayout",
+		"#Wareki",
+		"Epoch",
+		"EpochMin",
+		"EpochMax",
+		"#Code",
+		"#Formatted",
+		"Unit",
+		"Semantic",
+		"%Semantic",
+		"PII",
+		"%PII",
+		"PIIExample",
+		"Patterns",
+		"%Pattern",
+		"#OutlierIQR",
+		"#OutlierZ",
+		"Outliers",
 	} {
 		a.Equal(s, header[i], "differ header[%d] index element", i)
 	}
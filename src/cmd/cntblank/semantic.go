@@ -0,0 +1,147 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	valid "github.com/asaskevich/govalidator"
+)
+
+// SemanticProfile counts cells by semantic types of their values.
+// Each cell is counted as one type at most, which is examined in order
+// of fields, so that UUID is not counted as hexadecimal for example.
+type SemanticProfile struct {
+	JSON         int `json:"json"`         // JSON object or array
+	UUID         int `json:"uuid"`         // UUID such as "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	Email        int `json:"email"`        // email address
+	URL          int `json:"url"`          // URL with scheme such as "https://example.com/"
+	IPv4         int `json:"ipv4"`         // IPv4 address
+	IPv6         int `json:"ipv6"`         // IPv6 address
+	PostalCodeJP int `json:"postalCodeJP"` // Japanese postal code such as "123-4567"
+	PhoneJP      int `json:"phoneJP"`      // Japanese phone number such as "03-1234-5678"
+	Hex          int `json:"hex"`          // hexadecimal string such as hash value
+	Base64       int `json:"base64"`       // base64 encoded string
+}
+
+// Minimum length of hexadecimal and base64 strings, since short words
+// such as "cafe" and "data" are also valid as them.
+const (
+	minHexLength    = 8
+	minBase64Length = 16
+)
+
+var (
+	postalCodeJPPattern = regexp.MustCompile(`^〒? ?[0-9]{3}-[0-9]{4}$`)
+	phoneJPPattern      = regexp.MustCompile(`^(?:0|\+81[- ]?)[0-9]{1,4}(?:-[0-9]{1,4}-|\([0-9]{1,4}\)|[0-9]{1,4})[0-9]{3,4}$`)
+)
+
+// semanticType is a pair of the name of semantic type and its counter.
+type semanticType struct {
+	name  string
+	count func(p *SemanticProfile) *int
+	match func(string) bool
+}
+
+// semanticTypes are semantic types in order of examination.
+var semanticTypes = []semanticType{
+	{"json", func(p *SemanticProfile) *int { return &p.JSON }, isJSONContainer},
+	{"uuid", func(p *SemanticProfile) *int { return &p.UUID }, valid.IsUUID},
+	{"email", func(p *SemanticProfile) *int { return &p.Email }, valid.IsEmail},
+	{"url", func(p *SemanticProfile) *int { return &p.URL }, isURLWithScheme},
+	{"ipv4", func(p *SemanticProfile) *int { return &p.IPv4 }, valid.IsIPv4},
+	{"ipv6", func(p *SemanticProfile) *int { return &p.IPv6 }, valid.IsIPv6},
+	{"postal_code_jp", func(p *SemanticProfile) *int { return &p.PostalCodeJP }, postalCodeJPPattern.MatchString},
+	{"phone_jp", func(p *SemanticProfile) *int { return &p.PhoneJP }, isPhoneJP},
+	{"hex", func(p *SemanticProfile) *int { return &p.Hex }, isHexString},
+	{"base64", func(p *SemanticProfile) *int { return &p.Base64 }, isBase64String},
+}
+
+func (p *SemanticProfile) add(s string) {
+	for _, c := range semanticTypes {
+		if c.match(s) {
+			*c.count(p)++
+			return
+		}
+	}
+}
+
+// dominant returns the name and count of the most frequent semantic type.
+func (p *SemanticProfile) dominant() (name string, count int) {
+	for _, c := range semanticTypes {
+		if n := *c.count(p); n > count {
+			name, count = c.name, n
+		}
+	}
+	return
+}
+
+// isJSONContainer reports whether s is JSON object or array, since
+// numbers and quoted strings are valid JSON too.
+func isJSONContainer(s string) bool {
+	if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+		return false
+	}
+	return valid.IsJSON(s)
+}
+
+func isURLWithScheme(s string) bool {
+	return strings.Contains(s, "://") && valid.IsURL(s)
+}
+
+// isPhoneJP reports whether s is Japanese phone number, which has 10 or
+// 11 digits starting with 0, or country code +81 instead of the 0.
+func isPhoneJP(s string) bool {
+	if !phoneJPPattern.MatchString(s) {
+		return false
+	}
+	digits := 0
+	for _, r := range strings.TrimPrefix(s, "+81") {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	if strings.HasPrefix(s, "+81") {
+		digits++
+	}
+	return digits == 10 || digits == 11
+}
+
+func isHexString(s string) bool {
+	return len(s) >= minHexLength && !isDigits(s) && valid.IsHexadecimal(s)
+}
+
+// isBase64String reports whether s is base64 with padding, or mixes upper
+// and lower cases and digits, since identifiers of alphanumerics whose
+// length is a multiple of 4 are also valid as base64.
+func isBase64String(s string) bool {
+	if len(s) < minBase64Length || !valid.IsBase64(s) {
+		return false
+	}
+	if strings.HasSuffix(s, "=") {
+		return true
+	}
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// summarizeSemantic sets the most frequent semantic type and its ratio
+// to non-blank cells.
+func (f *ReportField) summarizeSemantic(records int) {
+	name, count := f.Semantics.dominant()
+	filled := records - f.Blank
+	if count == 0 || filled <= 0 {
+		return
+	}
+	f.SemanticType = name
+	f.SemanticRatio = float64(count) / float64(filled)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemanticProfile(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		s    string
+		want string
+	}{
+		{`{"a": 1}`, "json"},
+		{`[1, 2]`, "json"},
+		{"6ba7b810-9dad-11d1-80b4-00c04fd430c8", "uuid"},
+		{"foo@example.com", "email"},
+		{"https://example.com/path?q=1", "url"},
+		{"ftp://example.com/", "url"},
+		{"192.168.0.1", "ipv4"},
+		{"2001:db8::1", "ipv6"},
+		{"123-4567", "postal_code_jp"},
+		{"〒123-4567", "postal_code_jp"},
+		{"03-1234-5678", "phone_jp"},
+		{"090-1234-5678", "phone_jp"},
+		{"0312345678", "phone_jp"},
+		{"03(1234)5678", "phone_jp"},
+		{"+81-3-1234-5678", "phone_jp"},
+		{"d41d8cd98f00b204e9800998ecf8427e", "hex"},
+		{"SGVsbG8sIFdvcmxkIQ==", "base64"},
+		{"dGhpcyBpcyBhIHRlc3Q1", "base64"},
+		{"abcdefghijklmnop", ""},
+		{"ORDER2016ABCDEFG", ""},
+		{"", ""},
+		{"123", ""},
+		{"12345678", ""},
+		{"cafe", ""},
+		{"example.com", ""},
+		{"1.5", ""},
+		{`"quoted"`, ""},
+		{"03-1234-567", ""},
+		{"1234-567", ""},
+		{"北海道", ""},
+	} {
+		p := new(SemanticProfile)
+		p.add(tc.s)
+		name, count := p.dominant()
+		a.Equal(tc.want, name, "semantic type of %q", tc.s)
+		if tc.want != "" {
+			a.Equal(1, count, "%q should be counted once", tc.s)
+		}
+	}
+}
+
+func TestReportFieldSummarizeSemantic(t *testing.T) {
+	a := assert.New(t)
+	report := new(Report)
+	for _, s := range [][]string{
+		{"foo@example.com", "100-0001"},
+		{"bar@example.com", "x"},
+		{"", "060-0000"},
+		{"https://example.com/", ""},
+	} {
+		report.parseRecord(s)
+	}
+	report.summarize()
+	f := report.Fields[0]
+	a.Equal(2, f.Semantics.Email)
+	a.Equal(1, f.Semantics.URL)
+	a.Equal("email", f.SemanticType)
+	a.InDelta(2.0/3, f.SemanticRatio, 1e-9)
+	a.Equal([]string{"email", "0.6667"}, f.format(report.Records)[47:49])
+	f = report.Fields[1]
+	a.Equal("postal_code_jp", f.SemanticType)
+	a.InDelta(2.0/3, f.SemanticRatio, 1e-9)
+}
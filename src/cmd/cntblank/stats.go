@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+)
+
+// runningStats accumulates summary statistics of numbers in one pass.
+// Mean and variance are updated by Welford's method, and sum is
+// compensated by Kahan-Babuska summation to keep precision on large files.
+type runningStats struct {
+	n    int
+	mean float64
+	m2   float64
+	sum  float64
+	comp float64
+}
+
+func (s *runningStats) add(x float64) {
+	s.n++
+	delta := x - s.mean
+	s.mean += delta / float64(s.n)
+	s.m2 += delta * (x - s.mean)
+	t := s.sum + x
+	if math.Abs(s.sum) >= math.Abs(x) {
+		s.comp += (s.sum - t) + x
+	} else {
+		s.comp += (x - t) + s.sum
+	}
+	s.sum = t
+}
+
+// Sum returns the total of added numbers.
+func (s *runningStats) Sum() float64 {
+	return s.sum + s.comp
+}
+
+// Mean returns the arithmetic mean of added numbers.
+func (s *runningStats) Mean() float64 {
+	return s.mean
+}
+
+// Variance returns the unbiased sample variance of added numbers.
+func (s *runningStats) Variance() float64 {
+	if s.n < 2 {
+		return 0
+	}
+	return s.m2 / float64(s.n-1)
+}
+
+// StdDev returns the sample standard deviation of added numbers.
+func (s *runningStats) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
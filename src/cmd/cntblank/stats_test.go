@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunningStats(t *testing.T) {
+	a := assert.New(t)
+	s := runningStats{}
+	a.Equal(0.0, s.Variance())
+	for _, v := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		s.add(v)
+	}
+	a.Equal(8, s.n)
+	a.InDelta(40.0, s.Sum(), 1e-9)
+	a.InDelta(5.0, s.Mean(), 1e-9)
+	a.InDelta(32.0/7.0, s.Variance(), 1e-9)
+	a.InDelta(math.Sqrt(32.0/7.0), s.StdDev(), 1e-9)
+}
+
+func TestRunningStats_LargeOffset(t *testing.T) {
+	// Naive sum of squares loses all precision with this offset.
+	a := assert.New(t)
+	s := runningStats{}
+	for _, v := range []float64{4, 7, 13, 16} {
+		s.add(1e9 + v)
+	}
+	a.InDelta(1e9+10, s.Mean(), 1e-6)
+	a.InDelta(30.0, s.Variance(), 1e-6)
+	a.InDelta(4e9+40, s.Sum(), 1e-6)
+}
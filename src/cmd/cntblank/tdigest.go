@@ -0,0 +1,153 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// tdigest is a merging t-digest, which is a bounded-memory sketch to
+// estimate quantiles and cumulative distribution of a stream of numbers.
+// See https://github.com/tdunning/t-digest for the algorithm.
+type tdigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	count       float64
+	min         float64
+	max         float64
+}
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+type byMean []centroid
+
+func (a byMean) Len() int           { return len(a) }
+func (a byMean) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byMean) Less(i, j int) bool { return a[i].mean < a[j].mean }
+
+func newTDigest(compression float64) *tdigest {
+	return &tdigest{
+		compression: compression,
+		buffer:      make([]centroid, 0, int(compression)*5),
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+func (d *tdigest) add(x float64) {
+	if math.IsNaN(x) {
+		return
+	}
+	if x < d.min {
+		d.min = x
+	}
+	if x > d.max {
+		d.max = x
+	}
+	d.count++
+	d.buffer = append(d.buffer, centroid{x, 1})
+	if len(d.buffer) == cap(d.buffer) {
+		d.compress()
+	}
+}
+
+// scale is the k1 scale function which keeps centroids small near tails.
+func (d *tdigest) scale(q float64) float64 {
+	return d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// compress merges buffered values into centroids.
+func (d *tdigest) compress() {
+	if len(d.buffer) == 0 {
+		return
+	}
+	all := append(d.centroids, d.buffer...)
+	sort.Sort(byMean(all))
+	merged := make([]centroid, 0, len(d.centroids)+1)
+	merged = append(merged, all[0])
+	sofar := 0.0
+	for _, c := range all[1:] {
+		cur := &merged[len(merged)-1]
+		if d.scale((sofar+cur.weight+c.weight)/d.count)-d.scale(sofar/d.count) <= 1 {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+		} else {
+			sofar += cur.weight
+			merged = append(merged, c)
+		}
+	}
+	d.centroids = merged
+	d.buffer = d.buffer[:0]
+}
+
+// quantile returns estimated value at q which is in range [0, 1].
+func (d *tdigest) quantile(q float64) float64 {
+	d.compress()
+	if len(d.centroids) == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return d.min
+	}
+	if q >= 1 {
+		return d.max
+	}
+	target := q * d.count
+	first := d.centroids[0]
+	if target < first.weight/2 {
+		return d.min + (first.mean-d.min)*target/(first.weight/2)
+	}
+	cum := 0.0
+	for i := 0; i < len(d.centroids)-1; i++ {
+		c, next := d.centroids[i], d.centroids[i+1]
+		left := cum + c.weight/2
+		right := cum + c.weight + next.weight/2
+		if target <= right {
+			return c.mean + (next.mean-c.mean)*(target-left)/(right-left)
+		}
+		cum += c.weight
+	}
+	last := d.centroids[len(d.centroids)-1]
+	left := d.count - last.weight/2
+	if d.count == left {
+		return last.mean
+	}
+	return last.mean + (d.max-last.mean)*(target-left)/(d.count-left)
+}
+
+// cdf returns estimated fraction of values which are less than or equal to x.
+func (d *tdigest) cdf(x float64) float64 {
+	d.compress()
+	if len(d.centroids) == 0 {
+		return math.NaN()
+	}
+	if x < d.min {
+		return 0
+	}
+	if x >= d.max {
+		return 1
+	}
+	first := d.centroids[0]
+	if x < first.mean {
+		if first.mean == d.min {
+			return 0
+		}
+		return first.weight / 2 * (x - d.min) / (first.mean - d.min) / d.count
+	}
+	cum := 0.0
+	for i := 0; i < len(d.centroids)-1; i++ {
+		c, next := d.centroids[i], d.centroids[i+1]
+		if x < next.mean {
+			left := cum + c.weight/2
+			right := cum + c.weight + next.weight/2
+			return (left + (right-left)*(x-c.mean)/(next.mean-c.mean)) / d.count
+		}
+		cum += c.weight
+	}
+	last := d.centroids[len(d.centroids)-1]
+	left := d.count - last.weight/2
+	return (left + last.weight/2*(x-last.mean)/(d.max-last.mean)) / d.count
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTDigest_Empty(t *testing.T) {
+	d := newTDigest(100)
+	assert.True(t, math.IsNaN(d.quantile(0.5)))
+	assert.True(t, math.IsNaN(d.cdf(0)))
+}
+
+func TestTDigest_Small(t *testing.T) {
+	a := assert.New(t)
+	d := newTDigest(100)
+	for _, v := range []float64{5, 1, 3, 2, 4} {
+		d.add(v)
+	}
+	a.Equal(1.0, d.quantile(0))
+	a.Equal(5.0, d.quantile(1))
+	a.InDelta(3.0, d.quantile(0.5), 1e-9)
+	a.InDelta(0.5, d.cdf(3), 1e-9)
+	a.Equal(0.0, d.cdf(0))
+	a.Equal(1.0, d.cdf(5))
+}
+
+func TestTDigest_Uniform(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(1))
+	d := newTDigest(100)
+	for i := 0; i < 100000; i++ {
+		d.add(r.Float64() * 1000)
+	}
+	a.True(len(d.centroids) < 500, "centroids should be bounded: %d", len(d.centroids))
+	for _, q := range []float64{0.01, 0.05, 0.25, 0.5, 0.75, 0.95, 0.99} {
+		a.InDelta(q*1000, d.quantile(q), 5, "quantile %v", q)
+		a.InDelta(q, d.cdf(q*1000), 0.005, "cdf at %v", q*1000)
+	}
+}
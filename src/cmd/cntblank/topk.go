@@ -0,0 +1,111 @@
+package main
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// ValueCount represents a value and its frequency.
+// Count may be overestimated up to Error.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+	Error int    `json:"error,omitempty"`
+}
+
+// spaceSaving keeps approximate heavy hitters in bounded space by
+// Space-Saving algorithm of Metwally et al. Counts of monitored values
+// are upper bounds, which are exact while capacity is not exceeded.
+type spaceSaving struct {
+	capacity int
+	items    map[string]*topCounter
+	heap     topHeap
+}
+
+type topCounter struct {
+	value string
+	count int
+	error int
+	index int
+}
+
+// topHeap is a min-heap of counters ordered by count.
+type topHeap []*topCounter
+
+func (h topHeap) Len() int           { return len(h) }
+func (h topHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h topHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *topHeap) Push(x interface{}) {
+	c := x.(*topCounter)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *topHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{
+		capacity: capacity,
+		items:    make(map[string]*topCounter),
+	}
+}
+
+func (s *spaceSaving) add(value string) {
+	if c, ok := s.items[value]; ok {
+		c.count++
+		heap.Fix(&s.heap, c.index)
+		return
+	}
+	if len(s.heap) < s.capacity {
+		c := &topCounter{value: value, count: 1}
+		s.items[value] = c
+		heap.Push(&s.heap, c)
+		return
+	}
+	// Replace the least frequent value, which inherits its count.
+	c := s.heap[0]
+	delete(s.items, c.value)
+	c.value = value
+	c.error = c.count
+	c.count++
+	s.items[value] = c
+	heap.Fix(&s.heap, 0)
+}
+
+// top returns at most n values in descending order of count.
+// Values which may appear only once are omitted unless their counts are
+// exact, because they are noise on high cardinality values.
+func (s *spaceSaving) top(n int) []ValueCount {
+	values := make([]ValueCount, 0, len(s.heap))
+	for _, c := range s.heap {
+		if c.error == 0 || c.count-c.error > 1 {
+			values = append(values, ValueCount{c.value, c.count, c.error})
+		}
+	}
+	sort.Sort(byCount(values))
+	if len(values) > n {
+		values = values[:n]
+	}
+	return values
+}
+
+type byCount []ValueCount
+
+func (a byCount) Len() int      { return len(a) }
+func (a byCount) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byCount) Less(i, j int) bool {
+	if a[i].Count == a[j].Count {
+		return a[i].Value < a[j].Value
+	}
+	return a[i].Count > a[j].Count
+}
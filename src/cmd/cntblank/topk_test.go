@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpaceSaving(t *testing.T) {
+	a := assert.New(t)
+	s := newSpaceSaving(3)
+	a.Empty(s.top(3))
+	for _, v := range []string{"a", "b", "a", "c", "a", "b"} {
+		s.add(v)
+	}
+	a.Equal([]ValueCount{{"a", 3, 0}, {"b", 2, 0}, {"c", 1, 0}}, s.top(5))
+	a.Equal([]ValueCount{{"a", 3, 0}}, s.top(1))
+	// "d" replaces "c" which is the least frequent, and it is omitted
+	// because "d" may appear only once.
+	s.add("d")
+	a.Equal([]ValueCount{{"a", 3, 0}, {"b", 2, 0}}, s.top(3))
+	s.add("d")
+	a.Equal([]ValueCount{{"a", 3, 0}, {"d", 3, 1}, {"b", 2, 0}}, s.top(3))
+}
+
+func TestSpaceSaving_HeavyHitters(t *testing.T) {
+	a := assert.New(t)
+	s := newSpaceSaving(50)
+	for i := 0; i < 10000; i++ {
+		switch {
+		case i%10 == 0:
+			s.add("-")
+		case i%25 == 1:
+			s.add("不明")
+		default:
+			s.add(fmt.Sprint(i))
+		}
+	}
+	top := s.top(2)
+	a.Equal("-", top[0].Value)
+	a.Equal("不明", top[1].Value)
+	a.True(top[0].Count >= 1000, "count should be upper bound: %d", top[0].Count)
+	a.True(top[0].Count-top[0].Error <= 1000, "count minus error should be lower bound: %d", top[0].Count-top[0].Error)
+}
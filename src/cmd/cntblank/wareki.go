@@ -0,0 +1,138 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/width"
+)
+
+// warekiLayout is a pseudo layout to record dates in Japanese era.
+const warekiLayout = "wareki"
+
+// era is a Japanese imperial era, which starts at the date.
+type era struct {
+	name   string
+	letter string
+	start  time.Time
+}
+
+var eras = []era{
+	{"明治", "M", time.Date(1868, 1, 25, 0, 0, 0, 0, time.UTC)},
+	{"大正", "T", time.Date(1912, 7, 30, 0, 0, 0, 0, time.UTC)},
+	{"昭和", "S", time.Date(1926, 12, 25, 0, 0, 0, 0, time.UTC)},
+	{"平成", "H", time.Date(1989, 1, 8, 0, 0, 0, 0, time.UTC)},
+	{"令和", "R", time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)},
+}
+
+const kanjiNumber = `[0-9〇一二三四五六七八九十]{1,3}`
+
+var (
+	// 令和2年4月1日, 平成元年, 昭和六十四年一月七日
+	warekiKanjiPattern = regexp.MustCompile(`^(明治|大正|昭和|平成|令和|[MTSHR]) ?(元|` + kanjiNumber +
+		`) ?年(?: ?(` + kanjiNumber + `) ?月(?: ?(` + kanjiNumber + `) ?日)?)?$`)
+	// H31.4.30, R2/4/1, S64-01-07
+	warekiLetterPattern = regexp.MustCompile(`^([MTSHR])([0-9]{1,2})([./-])([0-9]{1,2})([./-])([0-9]{1,2})$`)
+)
+
+// parseWareki parses s as date in Japanese era, which is written with
+// era name in kanji or its initial letter, such as "令和2年4月1日" and
+// "H31.4.30". Full-width digits, kanji numerals and "元年" are accepted.
+func parseWareki(s string, location *time.Location) (t time.Time, ok bool) {
+	s = width.Narrow.String(strings.TrimSpace(s))
+	if s == "" {
+		return
+	}
+	var name, year, month, day string
+	if m := warekiLetterPattern.FindStringSubmatch(s); m != nil {
+		if m[3] != m[5] {
+			return
+		}
+		name, year, month, day = m[1], m[2], m[4], m[6]
+	} else if m := warekiKanjiPattern.FindStringSubmatch(s); m != nil {
+		name, year, month, day = m[1], m[2], m[3], m[4]
+	} else {
+		return
+	}
+	var y, mon, d int
+	if year == "元" {
+		y = 1
+	} else if y, ok = parseKanjiNumber(year); !ok {
+		return
+	}
+	mon, d = 1, 1
+	if month != "" {
+		if mon, ok = parseKanjiNumber(month); !ok {
+			return t, false
+		}
+	}
+	if day != "" {
+		if d, ok = parseKanjiNumber(day); !ok {
+			return t, false
+		}
+	}
+	for i, e := range eras {
+		if name != e.name && name != e.letter {
+			continue
+		}
+		if y < 1 || mon < 1 || mon > 12 || d < 1 || d > 31 {
+			return t, false
+		}
+		t = time.Date(e.start.Year()+y-1, time.Month(mon), d, 0, 0, 0, 0, location)
+		if t.Day() != d {
+			// Such as February 30th.
+			return t, false
+		}
+		// The year should not exceed the start of next era, but dates
+		// just after the change are often written in the old era.
+		if i+1 < len(eras) && t.Year() > eras[i+1].start.Year() {
+			return t, false
+		}
+		return t, true
+	}
+	return t, false
+}
+
+// parseKanjiNumber parses a number up to 99 in arabic or kanji numerals,
+// such as "31", "三十一" and "三一".
+func parseKanjiNumber(s string) (int, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, n >= 0 && n <= 99
+	}
+	n, digits := 0, 0
+	tens := -1
+	for _, r := range s {
+		v := strings.IndexRune("〇一二三四五六七八九", r)
+		switch {
+		case r == '十':
+			if tens >= 0 {
+				return 0, false
+			}
+			if digits == 0 {
+				n = 1
+			}
+			tens = n
+			n, digits = 0, 0
+		case v >= 0:
+			if digits == 2 {
+				return 0, false
+			}
+			n = n*10 + v/len("〇")
+			digits++
+		default:
+			return 0, false
+		}
+	}
+	if tens >= 0 {
+		if digits > 1 {
+			return 0, false
+		}
+		if tens > 9 {
+			return 0, false
+		}
+		return tens*10 + n, true
+	}
+	return n, digits > 0
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWareki(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		s    string
+		want string
+	}{
+		{"令和2年4月1日", "2020-04-01"},
+		{"令和元年5月1日", "2019-05-01"},
+		{"平成31年", "2019-01-01"},
+		{"平成31年4月", "2019-04-01"},
+		{"平成 31年 4月 30日", "2019-04-30"},
+		{"H31.4.30", "2019-04-30"},
+		{"R2/4/1", "2020-04-01"},
+		{"S64-01-07", "1989-01-07"},
+		{"Ｈ３１．４．３０", "2019-04-30"},
+		{"平成３１年４月３０日", "2019-04-30"},
+		{"昭和六十四年一月七日", "1989-01-07"},
+		{"平成三十一年四月三十日", "2019-04-30"},
+		{"大正十五年十二月二十五日", "1926-12-25"},
+		{"明治四五年七月二九日", "1912-07-29"},
+		{"H31年4月30日", "2019-04-30"},
+	} {
+		v, ok := parseWareki(tc.s, time.UTC)
+		a.True(ok, "%q should be parsed", tc.s)
+		a.Equal(tc.want, v.Format("2006-01-02"), "%q", tc.s)
+	}
+	for _, s := range []string{
+		"",
+		"平成",
+		"2019年4月30日",
+		"平成32年1月1日",
+		"平成31年2月30日",
+		"平成31年13月1日",
+		"平成0年",
+		"H31.4/30",
+		"X31.4.30",
+		"平成十十年",
+		"平成三十一一年",
+	} {
+		_, ok := parseWareki(s, time.UTC)
+		a.False(ok, "%q should not be parsed", s)
+	}
+}
+
+func TestParseKanjiNumber(t *testing.T) {
+	a := assert.New(t)
+	for s, want := range map[string]int{
+		"31":   31,
+		"一":    1,
+		"十":    10,
+		"十一":   11,
+		"二十":   20,
+		"三十一":  31,
+		"三一":   31,
+		"二〇":   20,
+		"九十九":  99,
+		"〇七":   7,
+		"01":   1,
+		"一二三":  0,
+		"123":  0,
+		"-1":   0,
+		"五十":   50,
+		"十九":   19,
+		"二十〇":  20,
+		"三十一日": 0,
+	} {
+		n, ok := parseKanjiNumber(s)
+		if want == 0 {
+			a.False(ok, "%q should not be parsed", s)
+			continue
+		}
+		a.True(ok, "%q should be parsed", s)
+		a.Equal(want, n, "%q", s)
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"strings"
 	"time"
+	"unicode"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/tealeg/xlsx"
+	"golang.org/x/text/width"
 
 	"csvhelper"
 )
@@ -41,6 +44,33 @@ type ReportExcelWriter struct {
 	dialect *csvhelper.FileDialect
 }
 
+// ReportTextWriter is a writer object to write report as aligned plain text.
+// It writes only textColumns unless allColumns is set.
+type ReportTextWriter struct {
+	w          io.Writer
+	dialect    *csvhelper.FileDialect
+	allColumns bool
+}
+
+// textColumns are columns of text output by default, which fit in console.
+var textColumns = []string{
+	"seq",
+	"Name",
+	"#Blank",
+	"%Blank",
+	"MinLength",
+	"MaxLength",
+	"Type",
+	"%Type",
+	"Minimum",
+	"Maximum",
+	"#Distinct",
+	"Key",
+	"TimeLayout",
+	"Semantic",
+	"PII",
+}
+
 // NewReportWriter returns a new ReportWriter that writes to w by given format.
 func NewReportWriter(w io.Writer, format Format, dialect *csvhelper.FileDialect) ReportWriter {
 	switch format {
@@ -68,6 +98,14 @@ func NewReportWriter(w io.Writer, format Format, dialect *csvhelper.FileDialect)
 		return &ReportHTMLWriter{
 			w: w,
 		}
+	case Text:
+		if dialect == nil {
+			dialect = &csvhelper.FileDialect{}
+		}
+		return &ReportTextWriter{
+			dialect: dialect,
+			w:       w,
+		}
 	}
 	log.Errorf("NewReportWriter: not implemented format %q", format)
 	return nil
@@ -96,6 +134,17 @@ func (w *ReportCSVWriter) writeCsvOne(writer *csv.Writer, report Report) error {
 			preamble[3] = report.MD5hex
 			writer.Write(preamble)
 		}
+		if len(report.Encoding) > 0 {
+			preamble[0] = "# Encoding"
+			preamble[1] = report.Encoding
+			if report.HasBOM {
+				preamble[2] = "(has BOM)"
+			} else {
+				preamble[2] = ""
+			}
+			preamble[3] = ""
+			writer.Write(preamble)
+		}
 		preamble[0] = "# Field"
 		preamble[1] = fmt.Sprint(len(report.Fields))
 		if report.HasHeader {
@@ -169,6 +218,48 @@ func (w *ReportHTMLWriter) Write(reports []Report) error {
 		"renderInt": func(i int) string {
 			return RenderInteger("#,###.", i)
 		},
+		"median": func(f *ReportField) string {
+			if f.useTime() && f.MedianTime != nil {
+				return f.MedianTime.Format("2006-01-02 15:04:05")
+			} else if !f.useTime() && f.Median != nil {
+				return RenderFloat("", *f.Median)
+			}
+			return ""
+		},
+		"quantiles": func(f *ReportField) string {
+			return f.formatQuantiles()
+		},
+		"lengthHistogram": func(f *ReportField) string {
+			return f.formatLengthHistogram()
+		},
+		"histogram": func(f *ReportField) string {
+			return f.formatHistogram()
+		},
+		"outliers": func(f *ReportField) string {
+			return f.formatOutliers()
+		},
+		"delimiterName": delimiterName,
+		"barWidth": func(count int, bins interface{}) int {
+			max := 0
+			switch bins := bins.(type) {
+			case []LengthBucket:
+				for _, b := range bins {
+					if b.Count > max {
+						max = b.Count
+					}
+				}
+			case []HistogramBin:
+				for _, b := range bins {
+					if b.Count > max {
+						max = b.Count
+					}
+				}
+			}
+			if max == 0 {
+				return 0
+			}
+			return count * 100 / max
+		},
 	}
 	tmpl, err := template.New("name").Funcs(fmap).Parse(fmt.Sprintf("%s", b))
 	if err != nil {
@@ -187,6 +278,10 @@ func (w *ReportExcelWriter) Write(reports []Report) error {
 	if err != nil {
 		return err
 	}
+	sheetHistograms, err := file.AddSheet("Histograms")
+	if err != nil {
+		return err
+	}
 	var row *xlsx.Row
 	// Put header line on Files sheet.
 	row = sheetFiles.AddRow()
@@ -195,6 +290,10 @@ func (w *ReportExcelWriter) Write(reports []Report) error {
 		"Path",
 		"File name",
 		"MD5 Checksum",
+		"Encoding",
+		"Has BOM",
+		"Delimiter",
+		"Quoting",
 		"Has header",
 		"#Fields",
 		"#Records",
@@ -208,18 +307,87 @@ func (w *ReportExcelWriter) Write(reports []Report) error {
 		"Name",
 		"#Blank",
 		"%Blank",
+		"#Empty",
+		"#WhiteSpace",
+		"#NullToken",
+		"#Padded",
+		"#IdeographicSpace",
 		"MinLength",
 		"MaxLength",
+		"Mean length",
+		"Max bytes (UTF-8)",
+		"Max bytes (Shift_JIS)",
+		"Length histogram",
 		"#Int",
 		"#Float",
 		"#Bool",
 		"#Time",
+		"Type",
+		"%Type",
 		"Minimum",
 		"Maximum",
 		"MinTime",
 		"MaxTime",
 		"#True",
 		"#False",
+		"Mean",
+		"Variance",
+		"StdDev",
+		"Sum",
+		"Median",
+		"MedianTime",
+		"Quantiles",
+		"#Distinct",
+		"%Distinct",
+		"Approximate",
+		"Candidate key",
+		"#FullWidth",
+		"#HalfWidthKana",
+		"#Hiragana",
+		"#Kanji",
+		"#ASCII",
+		"#Digits",
+		"#Control",
+		"#Replacement",
+		"Time layout",
+		"Mixed layouts",
+		"Wareki",
+		"Epoch unit",
+		"Code",
+		"Formatted",
+		"Unit",
+		"Semantic type",
+		"%Semantic",
+		"#JSON",
+		"#UUID",
+		"#Email",
+		"#URL",
+		"#IPv4",
+		"#IPv6",
+		"#Postal code (JP)",
+		"#Phone (JP)",
+		"#Hex",
+		"#Base64",
+		"PII type",
+		"%PII",
+		"PII example",
+		"Patterns",
+		"%Pattern",
+		"#Outlier (IQR)",
+		"#Outlier (z-score)",
+		"Outliers",
+	} {
+		w.addString(row, k)
+	}
+	// Put header line on Histograms sheet.
+	row = sheetHistograms.AddRow()
+	for _, k := range []string{
+		"File No.",
+		"Field No.",
+		"Name",
+		"From",
+		"To",
+		"Count",
 	} {
 		w.addString(row, k)
 	}
@@ -230,6 +398,10 @@ func (w *ReportExcelWriter) Write(reports []Report) error {
 		w.addString(row, report.Path)
 		w.addString(row, report.Filename)
 		w.addString(row, report.MD5hex)
+		w.addString(row, report.Encoding)
+		w.addBool(row, report.HasBOM)
+		w.addString(row, delimiterName(report.Delimiter))
+		w.addString(row, report.Quoting)
 		w.addBool(row, report.HasHeader)
 		w.addInt(row, len(report.Fields))
 		w.addInt(row, report.Records)
@@ -257,6 +429,7 @@ func (w *ReportExcelWriter) Write(reports []Report) error {
 		w.addInt(row, report.Records)
 		w.addString(row, "records")
 		w.writeFields(sheetFields, report.Fields, report.Records)
+		w.writeHistograms(sheetHistograms, i+1, report.Fields)
 	}
 	return file.Write(w.w)
 }
@@ -273,12 +446,23 @@ func (w *ReportExcelWriter) writeFields(sheet *xlsx.Sheet, fields []*ReportField
 		} else {
 			w.addString(row, "N/A divided by 0")
 		}
+		w.addInt(row, field.Empty)
+		w.addInt(row, field.WhiteSpace)
+		w.addInt(row, field.NullToken)
+		w.addInt(row, field.Padded)
+		w.addInt(row, field.IdeographicSpace)
 		w.addInt(row, field.MinLength)
 		w.addInt(row, field.MaxLength)
+		w.addFloat(row, field.MeanLength)
+		w.addInt(row, field.MaxByteLength)
+		w.addInt(row, field.MaxSJISByteLength)
+		w.addString(row, field.formatLengthHistogram())
 		w.addInt(row, field.TypeInt)
 		w.addInt(row, field.TypeFloat)
 		w.addInt(row, field.TypeBool)
 		w.addInt(row, field.TypeTime)
+		w.addString(row, field.InferredType.String())
+		w.addFloat(row, field.TypeConfidence)
 		if field.Minimum != nil {
 			w.addFloat(row, *field.Minimum)
 		} else {
@@ -309,6 +493,68 @@ func (w *ReportExcelWriter) writeFields(sheet *xlsx.Sheet, fields []*ReportField
 		} else {
 			w.addString(row, "")
 		}
+		for _, v := range []*float64{field.Mean, field.Variance, field.StdDev, field.Sum, field.Median} {
+			if v != nil {
+				w.addFloat(row, *v)
+			} else {
+				w.addString(row, "")
+			}
+		}
+		if field.MedianTime != nil {
+			w.addTime(row, *field.MedianTime)
+		} else {
+			w.addString(row, "")
+		}
+		w.addString(row, field.formatQuantiles())
+		w.addInt(row, field.Distinct)
+		w.addFloat(row, field.DistinctRatio)
+		w.addBool(row, field.DistinctApprox)
+		w.addBool(row, field.CandidateKey)
+		c := field.Characters
+		for _, n := range []int{c.FullWidth, c.HalfWidthKana, c.Hiragana, c.Kanji,
+			c.ASCII, c.Digits, c.Control, c.Replacement} {
+			w.addInt(row, n)
+		}
+		w.addString(row, field.TimeLayout)
+		w.addBool(row, field.MixedTimeLayouts)
+		w.addInt(row, field.TypeWareki)
+		w.addString(row, field.EpochUnit)
+		w.addInt(row, field.TypeCode)
+		w.addInt(row, field.TypeFormatted)
+		w.addString(row, field.NumberUnit)
+		w.addString(row, field.SemanticType)
+		w.addFloat(row, field.SemanticRatio)
+		for _, t := range semanticTypes {
+			w.addInt(row, *t.count(&field.Semantics))
+		}
+		w.addString(row, field.PIIType)
+		w.addFloat(row, field.PIIRatio)
+		w.addString(row, field.PIIExample)
+		w.addString(row, field.formatPatterns())
+		w.addFloat(row, field.PatternCoverage)
+		w.addInt(row, field.OutliersIQR)
+		w.addInt(row, field.OutliersZScore)
+		w.addString(row, field.formatOutliers())
+	}
+}
+
+func (w *ReportExcelWriter) writeHistograms(sheet *xlsx.Sheet, fileNo int, fields []*ReportField) {
+	var row *xlsx.Row
+	for i, field := range fields {
+		for _, b := range field.Histogram {
+			row = sheet.AddRow()
+			w.addInt(row, fileNo)
+			w.addInt(row, i+1)
+			w.addString(row, field.Name)
+			if b.FromTime != nil {
+				w.addTime(row, *b.FromTime)
+				w.addTime(row, *b.ToTime)
+			} else {
+				w.addFloat(row, *b.From)
+				w.addFloat(row, *b.To)
+			}
+			w.addInt(row, b.Count)
+		}
 	}
 }
 
@@ -336,3 +582,142 @@ func (w *ReportExcelWriter) addTime(row *xlsx.Row, value time.Time) {
 	cell := row.AddCell()
 	cell.SetDateTime(value)
 }
+
+func (w *ReportTextWriter) Write(reports []Report) error {
+	for i, report := range reports {
+		if i > 0 {
+			if _, err := io.WriteString(w.w, "\n"); err != nil {
+				return err
+			}
+		}
+		log.Debugf("[%d] write text table", i+1)
+		if err := w.writeTextOne(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columns returns indexes of columns to write.
+func (w *ReportTextWriter) columns() []int {
+	header := ReportField{}.header()
+	var columns []int
+	for i, name := range header {
+		if w.allColumns {
+			columns = append(columns, i)
+			continue
+		}
+		for _, c := range textColumns {
+			if name == c {
+				columns = append(columns, i)
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// pick returns cells of row at indexes of columns.
+func pick(row []string, columns []int) []string {
+	cells := make([]string, len(columns))
+	for i, j := range columns {
+		cells[i] = row[j]
+	}
+	return cells
+}
+
+func (w *ReportTextWriter) writeTextOne(report Report) error {
+	var lines []string
+	if w.dialect.HasMetadata {
+		if len(report.Path) > 0 {
+			lines = append(lines, fmt.Sprintf("# File: %s (%s) %s", report.Path, report.Filename, report.MD5hex))
+		}
+		if len(report.Encoding) > 0 {
+			bom := ""
+			if report.HasBOM {
+				bom = " (has BOM)"
+			}
+			lines = append(lines, fmt.Sprintf("# Encoding: %s%s", report.Encoding, bom))
+		}
+		header := ""
+		if report.HasHeader {
+			header = " (has header)"
+		}
+		lines = append(lines, fmt.Sprintf("# Field: %d%s", len(report.Fields), header))
+		lines = append(lines, fmt.Sprintf("# Record: %d", report.Records))
+	}
+	columns := w.columns()
+	var rows [][]string
+	if w.dialect.HasHeader {
+		rows = append(rows, pick(ReportField{}.header(), columns))
+	}
+	for i, f := range report.Fields {
+		r := f.format(report.Records)
+		r[0] = fmt.Sprint(i + 1)
+		rows = append(rows, pick(r, columns))
+	}
+	if len(rows) > 0 {
+		// Measure each column by display width to align East Asian wide characters.
+		widths := make([]int, len(rows[0]))
+		for _, row := range rows {
+			for j, cell := range row {
+				if n := displayWidth(cell); n > widths[j] {
+					widths[j] = n
+				}
+			}
+		}
+		border := make([]string, len(widths))
+		for j, n := range widths {
+			border[j] = strings.Repeat("-", n+2)
+		}
+		separator := "+" + strings.Join(border, "+") + "+"
+		lines = append(lines, separator)
+		for i, row := range rows {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				cells[j] = " " + cell + strings.Repeat(" ", widths[j]-displayWidth(cell)) + " "
+			}
+			lines = append(lines, "|"+strings.Join(cells, "|")+"|")
+			if i == 0 && w.dialect.HasHeader {
+				lines = append(lines, separator)
+			}
+		}
+		lines = append(lines, separator)
+	}
+	for _, line := range lines {
+		if _, err := io.WriteString(w.w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayWidth returns the number of columns to display s on console,
+// where East Asian wide and full-width characters occupy two columns.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.Is(unicode.Mn, r) || unicode.IsControl(r) {
+			// Combining marks and control characters have no width.
+			continue
+		}
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// delimiterName returns readable name of delimiter, such as "TAB".
+func delimiterName(delimiter string) string {
+	switch delimiter {
+	case "\t":
+		return "TAB"
+	case " ":
+		return "SPACE"
+	}
+	return delimiter
+}
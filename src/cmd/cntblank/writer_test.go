@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,6 +22,8 @@ func TestNewReportWriter(t *testing.T) {
 	a.NotNil(w)
 	w = NewReportWriter(nil, Excel, nil)
 	a.NotNil(w)
+	w = NewReportWriter(nil, Text, nil)
+	a.NotNil(w)
 }
 
 func TestReportWriterWithHeader(t *testing.T) {
@@ -34,7 +37,7 @@ func TestReportWriterWithHeader(t *testing.T) {
 		t.Errorf("Unexpected error: %s\n", err)
 	}
 	out := buffer.String()
-	expected := "seq,Name,#Blank,%Blank,MinLength,MaxLength,#Int,#Float,#Bool,#Time,Minimum,Maximum,#True,#False\n"
+	expected := "seq,Name,#Blank,%Blank,#Empty,#WhiteSpace,#NullToken,#Padded,#IdeographicSpace,MinLength,MaxLength,#Int,#Float,#Bool,#Time,Type,%Type,Minimum,Maximum,#True,#False,Mean,Variance,StdDev,Sum,Median,Quantiles,#Distinct,%Distinct,Key,#FullWidth,#HalfWidthKana,#Hiragana,#Kanji,#ASCII,#Digits,#Control,#Replacement,TimeLayout,MixedLayout,#Wareki,Epoch,EpochMin,EpochMax,#Code,#Formatted,Unit,Semantic,%Semantic,PII,%PII,PIIExample,Patterns,%Pattern,#OutlierIQR,#OutlierZ,Outliers\n"
 	if out != expected {
 		t.Errorf("out=%q want %q", out, expected)
 	}
@@ -54,12 +57,36 @@ func TestReportWriterWithMetadata(t *testing.T) {
 	out := buffer.String()
 	expected := "# Field,0,,\n"
 	expected += "# Record,0,,\n"
-	expected += "seq,Name,#Blank,%Blank,MinLength,MaxLength,#Int,#Float,#Bool,#Time,Minimum,Maximum,#True,#False\n"
+	expected += "seq,Name,#Blank,%Blank,#Empty,#WhiteSpace,#NullToken,#Padded,#IdeographicSpace,MinLength,MaxLength,#Int,#Float,#Bool,#Time,Type,%Type,Minimum,Maximum,#True,#False,Mean,Variance,StdDev,Sum,Median,Quantiles,#Distinct,%Distinct,Key,#FullWidth,#HalfWidthKana,#Hiragana,#Kanji,#ASCII,#Digits,#Control,#Replacement,TimeLayout,MixedLayout,#Wareki,Epoch,EpochMin,EpochMax,#Code,#Formatted,Unit,Semantic,%Semantic,PII,%PII,PIIExample,Patterns,%Pattern,#OutlierIQR,#OutlierZ,Outliers\n"
 	if out != expected {
 		t.Errorf("out=%q want %q", out, expected)
 	}
 }
 
+func TestReportWriterWithEncoding(t *testing.T) {
+	a := assert.New(t)
+	dialect, err := csvhelper.NewFileDialect("", "", false)
+	a.Nil(err)
+	dialect.HasMetadata = true
+	for _, tc := range []struct {
+		encoding string
+		hasBOM   bool
+		csv      string
+		text     string
+	}{
+		{"utf8", true, "# Encoding,utf8,(has BOM),\n", "# Encoding: utf8 (has BOM)\n"},
+		{"sjis", false, "# Encoding,sjis,,\n", "# Encoding: sjis\n"},
+	} {
+		report := Report{Encoding: tc.encoding, HasBOM: tc.hasBOM}
+		buffer := &bytes.Buffer{}
+		a.Nil(NewReportWriter(buffer, CSV, dialect).Write([]Report{report}))
+		a.Equal(tc.csv+"# Field,0,,\n# Record,0,,\n", buffer.String())
+		buffer.Reset()
+		a.Nil(NewReportWriter(buffer, Text, dialect).Write([]Report{report}))
+		a.True(strings.HasPrefix(buffer.String(), tc.text+"# Field: 0\n"), "unexpected preamble: %q", buffer.String())
+	}
+}
+
 func TestReportWriterWithoutMetadata(t *testing.T) {
 	buffer := &bytes.Buffer{}
 	w := NewReportWriter(buffer, CSV, nil)
@@ -87,3 +114,48 @@ func TestReportWriter_JSON(t *testing.T) {
 	a.Nil(err)
 	a.Equal(expected, buffer.String())
 }
+
+func TestReportWriter_Text(t *testing.T) {
+	a := assert.New(t)
+	buffer := &bytes.Buffer{}
+	dialect, err := csvhelper.NewFileDialect("", "", true)
+	a.Nil(err)
+	w := NewReportWriter(buffer, Text, dialect)
+	report := Report{}
+	report.header([]string{"都道府県コード", "code"})
+	report.parseRecord([]string{"01", "北海道"})
+	report.parseRecord([]string{"02", ""})
+	err = w.Write([]Report{report})
+	a.Nil(err)
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	a.Equal(6, len(lines))
+	for i, line := range lines {
+		a.Equal(displayWidth(lines[0]), displayWidth(line), "line %d is not aligned: %q", i+1, line)
+	}
+	a.True(strings.HasPrefix(lines[3], "| 1   | 都道府県コード | 0      |"), "unexpected row: %q", lines[3])
+	a.Equal(len(textColumns), strings.Count(lines[1], "|")-1, "compact columns by default")
+	a.Contains(lines[1], "| Type ")
+
+	buffer.Reset()
+	w.(*ReportTextWriter).allColumns = true
+	a.Nil(w.Write([]Report{report}))
+	lines = strings.Split(buffer.String(), "\n")
+	a.Equal(len(ReportField{}.header()), strings.Count(lines[1], "|")-1, "all columns with option")
+}
+
+func TestDisplayWidth(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"seq", 3},
+		{"都道府県", 8},
+		{"ﾎｯｶｲﾄﾞｳ", 7},
+		{"Ｃｏｄｅ", 8},
+		{"コード1", 7},
+	} {
+		a.Equal(tc.want, displayWidth(tc.s), "width of %q", tc.s)
+	}
+}
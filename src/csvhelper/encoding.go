@@ -0,0 +1,179 @@
+package csvhelper
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saintfish/chardet"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// EncodingAuto is an encoding name to detect encoding of input.
+const EncodingAuto = "auto"
+
+// detectionSize is size of prefix to detect encoding.
+const detectionSize = 64 * 1024
+
+// encodings is the registry of encodings by lower case name.
+var encodings = map[string]encoding.Encoding{
+	"utf8":        encoding.Nop,
+	"utf-8":       encoding.Nop,
+	"sjis":        japanese.ShiftJIS,
+	"shift_jis":   japanese.ShiftJIS,
+	"cp932":       japanese.ShiftJIS,
+	"windows-31j": japanese.ShiftJIS,
+	"euc-jp":      japanese.EUCJP,
+	"eucjp":       japanese.EUCJP,
+	"iso-2022-jp": japanese.ISO2022JP,
+	"jis":         japanese.ISO2022JP,
+	"utf-16le":    unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	"utf-16be":    unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	"latin1":      charmap.ISO8859_1,
+	"iso-8859-1":  charmap.ISO8859_1,
+	"gbk":         simplifiedchinese.GBK,
+	"gb18030":     simplifiedchinese.GB18030,
+	"big5":        traditionalchinese.Big5,
+}
+
+// detectedEncodings maps charset names of detector to the registry.
+var detectedEncodings = map[string]string{
+	"UTF-8":       "utf8",
+	"UTF-16LE":    "utf-16le",
+	"UTF-16BE":    "utf-16be",
+	"Shift_JIS":   "sjis",
+	"EUC-JP":      "euc-jp",
+	"ISO-2022-JP": "iso-2022-jp",
+	"ISO-8859-1":  "latin1",
+	"GB-18030":    "gb18030",
+	"Big5":        "big5",
+}
+
+// boms maps encodings to their byte order marks.
+var boms = []struct {
+	encoding string
+	bom      []byte
+}{
+	{"utf8", []byte{0xef, 0xbb, 0xbf}},
+	{"utf-16le", []byte{0xff, 0xfe}},
+	{"utf-16be", []byte{0xfe, 0xff}},
+}
+
+// Encodings returns sorted names of available encodings.
+func Encodings() []string {
+	names := make([]string, 0, len(encodings))
+	for name := range encodings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupEncoding returns encoding of the name, which is case-insensitive.
+// Empty name is UTF-8.
+func LookupEncoding(name string) (encoding.Encoding, error) {
+	if name == "" {
+		return encoding.Nop, nil
+	}
+	if e, ok := encodings[strings.ToLower(name)]; ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("unknown encoding %q", name)
+}
+
+// DetectEncoding detects encoding of r by byte order mark, or statistical
+// detection on its prefix. It returns a reader which reads r from the
+// beginning and name of the encoding in the registry.
+// UTF-8 is the fallback when the encoding is unknown.
+// The reader is returned even on error, which occurs again on reading it.
+func DetectEncoding(r io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReaderSize(r, detectionSize)
+	prefix, err := br.Peek(detectionSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return br, "", err
+	}
+	for _, b := range boms {
+		if bytes.HasPrefix(prefix, b.bom) {
+			return br, b.encoding, nil
+		}
+	}
+	// ISO-2022-JP is 7-bit encoding, which switches character sets by
+	// escape sequences.
+	if bytes.Contains(prefix, []byte("\x1b$B")) || bytes.Contains(prefix, []byte("\x1b$@")) {
+		return br, "iso-2022-jp", nil
+	}
+	if validUTF8Prefix(prefix) {
+		return br, "utf8", nil
+	}
+	// Take the most confident one in the registry, since confidence is low
+	// on short text.
+	results, err := chardet.NewTextDetector().DetectAll(prefix)
+	if err != nil {
+		return br, "utf8", nil
+	}
+	for _, result := range results {
+		if name, ok := detectedEncodings[result.Charset]; ok {
+			return br, name, nil
+		}
+	}
+	return br, "utf8", nil
+}
+
+// SkipBOM skips byte order mark at the beginning of r. It returns a reader
+// which reads r after BOM, and the encoding implied by BOM, which is
+// "utf8", "utf-16le" or "utf-16be". The encoding is empty without BOM.
+// The reader is returned even on error, which occurs again on reading it.
+func SkipBOM(r io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReader(r)
+	prefix, err := br.Peek(3)
+	if err != nil && err != io.EOF {
+		return br, "", err
+	}
+	for _, b := range boms {
+		if bytes.HasPrefix(prefix, b.bom) {
+			br.Discard(len(b.bom))
+			return br, b.encoding, nil
+		}
+	}
+	return br, "", nil
+}
+
+// BOM returns byte order mark of the encoding, or nil if it has no BOM.
+func BOM(name string) []byte {
+	if name == "" {
+		name = "utf8"
+	}
+	name = strings.ToLower(name)
+	if name == "utf-8" {
+		name = "utf8"
+	}
+	for _, b := range boms {
+		if name == b.encoding {
+			return b.bom
+		}
+	}
+	return nil
+}
+
+// validUTF8Prefix reports whether b is valid UTF-8, where the last rune
+// may be cut in the middle. Pure ASCII is also valid.
+func validUTF8Prefix(b []byte) bool {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				b = b[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(b)
+}
@@ -0,0 +1,170 @@
+package csvhelper
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupEncoding(t *testing.T) {
+	for _, name := range []string{"", "utf8", "SJIS", "cp932", "euc-jp", "iso-2022-jp", "utf-16le", "utf-16be", "latin1", "gbk", "big5"} {
+		e, err := LookupEncoding(name)
+		assert.Nil(t, err, name)
+		assert.NotNil(t, e, name)
+	}
+	_, err := LookupEncoding("auto")
+	assert.NotNil(t, err, "auto is not an encoding")
+	_, err = LookupEncoding("ebcdic")
+	assert.NotNil(t, err)
+	assert.Contains(t, Encodings(), "euc-jp")
+}
+
+func TestCsvReaderWriterEncoding(t *testing.T) {
+	records := [][]string{{"都道府県", "Ünïcödé"}, {"東京都", "ok"}}
+	for _, tc := range []struct {
+		encoding string
+		records  [][]string
+	}{
+		{"utf8", records},
+		{"sjis", records[1:]},
+		{"euc-jp", records[1:]},
+		{"iso-2022-jp", records[1:]},
+		{"utf-16le", records},
+		{"utf-16be", records},
+		{"latin1", [][]string{{"Ünïcödé", "ok"}}},
+		{"gbk", records[1:]},
+		{"big5", [][]string{{"臺北市", "ok"}}},
+	} {
+		var b bytes.Buffer
+		d := &FileDialect{Comma: ',', Encoding: tc.encoding}
+		w := NewCsvWriter(&b, d)
+		require.Nil(t, w.WriteAll(tc.records), tc.encoding)
+		r := NewCsvReader(&b, d)
+		actual, err := r.ReadAll()
+		assert.Nil(t, err, tc.encoding)
+		assert.Equal(t, tc.records, actual, tc.encoding)
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	text := strings.Repeat("都道府県コード,都道府県名,市区町村名\n01,北海道,札幌市\n13,東京都,千代田区\n", 10)
+	for _, tc := range []struct {
+		encoding string
+		bom      []byte
+		text     string
+	}{
+		{"utf8", nil, text},
+		{"utf8", nil, "code,name\n01,foo\n"},
+		{"utf8", []byte{0xef, 0xbb, 0xbf}, text},
+		{"utf-16le", []byte{0xff, 0xfe}, text},
+		{"utf-16be", []byte{0xfe, 0xff}, text},
+		{"sjis", nil, text},
+		{"euc-jp", nil, text},
+		{"iso-2022-jp", nil, text},
+	} {
+		e, err := LookupEncoding(tc.encoding)
+		require.Nil(t, err)
+		encoded, err := e.NewEncoder().String(tc.text)
+		require.Nil(t, err)
+		r, name, err := DetectEncoding(bytes.NewReader(append(tc.bom, encoded...)))
+		assert.Nil(t, err)
+		assert.Equal(t, tc.encoding, name, "encoding of %s", tc.encoding)
+		b, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, len(tc.bom)+len(encoded), len(b), "reader should read from the beginning")
+	}
+}
+
+func TestValidUTF8Prefix(t *testing.T) {
+	s := []byte("東京")
+	assert.True(t, validUTF8Prefix(s))
+	assert.True(t, validUTF8Prefix(s[:len(s)-1]), "last rune is cut")
+	assert.True(t, validUTF8Prefix(s[:len(s)-2]), "last rune is cut")
+	assert.False(t, validUTF8Prefix([]byte{0x93, 0x8c, 0x8b, 0x9e}))
+}
+
+func TestNewCsvReaderAuto(t *testing.T) {
+	e, _ := LookupEncoding("sjis")
+	encoded, err := e.NewEncoder().String(strings.Repeat("名前,住所\n山田太郎,東京都千代田区\n", 10))
+	require.Nil(t, err)
+	r := NewCsvReader(strings.NewReader(encoded), &FileDialect{Comma: ',', Encoding: EncodingAuto})
+	record, err := r.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"名前", "住所"}, record)
+}
+
+func TestSkipBOM(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		encoding string
+		rest     string
+	}{
+		{"\xef\xbb\xbfid,name", "utf8", "id,name"},
+		{"\xff\xfei\x00d\x00", "utf-16le", "i\x00d\x00"},
+		{"\xfe\xff\x00i\x00d", "utf-16be", "\x00i\x00d"},
+		{"id,name", "", "id,name"},
+		{"\xef\xbb", "", "\xef\xbb"},
+		{"", "", ""},
+	} {
+		r, name, err := SkipBOM(strings.NewReader(tc.input))
+		assert.Nil(t, err)
+		assert.Equal(t, tc.encoding, name, "encoding of %q", tc.input)
+		b, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.rest, string(b))
+	}
+	assert.Equal(t, []byte{0xef, 0xbb, 0xbf}, BOM(""))
+	assert.Equal(t, []byte{0xef, 0xbb, 0xbf}, BOM("UTF-8"))
+	assert.Equal(t, []byte{0xff, 0xfe}, BOM("utf-16le"))
+	assert.Nil(t, BOM("sjis"))
+}
+
+func TestCsvReaderBOM(t *testing.T) {
+	e, _ := LookupEncoding("utf-16le")
+	utf16, err := e.NewEncoder().String("id,name\n1,東京\n")
+	require.Nil(t, err)
+	for _, tc := range []struct {
+		input    string
+		encoding string
+	}{
+		{"\xef\xbb\xbfid,name\n1,東京\n", ""},
+		{"\xef\xbb\xbfid,name\n1,東京\n", "auto"},
+		{"\xff\xfe" + utf16, "utf8"}, // BOM takes precedence.
+	} {
+		r := NewCsvReader(strings.NewReader(tc.input), &FileDialect{Comma: ',', Encoding: tc.encoding})
+		records, err := r.ReadAll()
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{{"id", "name"}, {"1", "東京"}}, records)
+	}
+}
+
+func TestCsvWriterBOM(t *testing.T) {
+	for _, tc := range []struct {
+		encoding string
+		expected string
+	}{
+		{"", "\xef\xbb\xbfa\n"},
+		{"utf8", "\xef\xbb\xbfa\n"},
+		{"utf-16be", "\xfe\xff\x00a\x00\n"},
+		{"sjis", "a\n"},
+	} {
+		var b bytes.Buffer
+		w := NewCsvWriter(&b, &FileDialect{Comma: ',', Encoding: tc.encoding, WriteBOM: true})
+		w.Write([]string{"a"})
+		w.Flush()
+		assert.Equal(t, tc.expected, b.String(), "BOM of %s", tc.encoding)
+		r := NewCsvReader(&b, &FileDialect{Comma: ',', Encoding: tc.encoding})
+		record, err := r.Read()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a"}, record)
+	}
+	var b bytes.Buffer
+	w := NewCsvWriter(&b, &FileDialect{Comma: ','})
+	w.Write([]string{"a"})
+	w.Flush()
+	assert.Equal(t, "a\n", b.String(), "no BOM by default")
+}
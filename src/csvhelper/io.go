@@ -4,23 +4,29 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"strings"
 	"unicode/utf8"
 
-	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding"
 	"golang.org/x/text/transform"
 )
 
 // FileDialect is a configuration for reader and writer.
 type FileDialect struct {
-	Comma            rune   // field delimiter (set to ',' by NewReader)
-	Comment          rune   // comment character for start of line
-	Encoding         string // file encoding (utf8 or sjis only)
-	FieldsPerRecord  int    // number of expected fields per record
-	HasHeader        bool   // CSV file has header line
-	HasMetadata      bool   // meta data before header line
-	LazyQuotes       bool   // allow lazy quotes
-	SheetNumber      int    // sheet number in Excel file which starts with 1
-	TrimLeadingSpace bool   // trim leading space
+	Comma            rune     // field delimiter (set to ',' by NewReader)
+	Comment          rune     // comment character for start of line
+	Encoding         string   // file encoding in the registry or "auto"
+	FieldsPerRecord  int      // number of expected fields per record
+	HasHeader        bool     // CSV file has header line
+	HasMetadata      bool     // meta data before header line
+	LazyQuotes       bool     // allow lazy quotes
+	NullValues       []string // tokens treated as null such as "NULL" and "\N"
+	NullIgnoreCase   bool     // compare null tokens in case-insensitive
+	Quoting          string   // quoting style inferred by SniffDialect
+	SheetNumber      int      // sheet number in Excel file which starts with 1
+	Sniff            bool     // infer delimiter, quoting and header by SniffDialect
+	TrimLeadingSpace bool     // trim leading space
+	WriteBOM         bool     // write byte order mark of UTF-8 and UTF-16
 }
 
 var defaults = FileDialect{
@@ -44,8 +50,10 @@ func NewFileDialect(delimiter, encoding string, hasHeader bool) (*FileDialect, e
 		}
 		comma = c
 	}
-	if len(encoding) > 0 {
-		// TODO: define the list of encodings and check the given is included the list.
+	if len(encoding) > 0 && encoding != EncodingAuto {
+		if _, err := LookupEncoding(encoding); err != nil {
+			return nil, err
+		}
 	}
 	return &FileDialect{
 		Comma:            comma,
@@ -58,30 +66,64 @@ func NewFileDialect(delimiter, encoding string, hasHeader bool) (*FileDialect, e
 	}, nil
 }
 
+// IsNull reports whether s is one of null tokens.
+func (d *FileDialect) IsNull(s string) bool {
+	for _, v := range d.NullValues {
+		if s == v || (d.NullIgnoreCase && strings.EqualFold(s, v)) {
+			return true
+		}
+	}
+	return false
+}
+
 // NewCsvReader creates new csv reader instance.
 func NewCsvReader(r io.Reader, d *FileDialect) (reader *csv.Reader) {
-	// TODO: separate the logic to switch decoder based on encoding
-	if d.Encoding == "sjis" {
-		decoder := japanese.ShiftJIS.NewDecoder()
-		reader = csv.NewReader(transform.NewReader(r, decoder))
-	} else {
-		reader = csv.NewReader(r)
+	return newCsvReader(decodeReader(r, d), d)
+}
+
+// NewCsvLineReader creates new csv reader instance, and a counter of lines
+// read by it, which gives line numbers of records.
+func NewCsvLineReader(r io.Reader, d *FileDialect) (*csv.Reader, *LineCounter) {
+	counter := NewLineCounter(decodeReader(r, d))
+	return newCsvReader(counter, d), counter
+}
+
+// decodeReader returns a reader which decodes r in encoding of d.
+func decodeReader(r io.Reader, d *FileDialect) io.Reader {
+	// Byte order mark takes precedence over the given encoding.
+	r, name, _ := SkipBOM(r)
+	if name == "" {
+		name = d.Encoding
+	}
+	if name == EncodingAuto {
+		r, name, _ = DetectEncoding(r)
+	}
+	if e, err := LookupEncoding(name); err == nil && e != encoding.Nop {
+		r = transform.NewReader(r, e.NewDecoder())
 	}
+	return r
+}
+
+func newCsvReader(r io.Reader, d *FileDialect) (reader *csv.Reader) {
+	reader = csv.NewReader(r)
 	reader.Comma = d.Comma
 	reader.Comment = d.Comment
+	reader.LazyQuotes = d.LazyQuotes
 	reader.FieldsPerRecord = d.FieldsPerRecord
 	return reader
 }
 
 // NewCsvWriter creates new csv writer instance.
+// Byte order mark is written first if it is required by dialect.
 func NewCsvWriter(w io.Writer, d *FileDialect) (writer *csv.Writer) {
-	// TODO: separate the logic to switch encoder based on encoding
-	if d.Encoding == "sjis" {
-		encoder := japanese.ShiftJIS.NewEncoder()
-		writer = csv.NewWriter(transform.NewWriter(w, encoder))
-	} else {
-		writer = csv.NewWriter(w)
+	if d.WriteBOM {
+		// Error occurs again on writing records.
+		w.Write(BOM(d.Encoding))
+	}
+	if e, err := LookupEncoding(d.Encoding); err == nil && e != encoding.Nop {
+		w = transform.NewWriter(w, e.NewEncoder())
 	}
+	writer = csv.NewWriter(w)
 	writer.Comma = d.Comma
 	return writer
 }
@@ -45,11 +45,15 @@ func TestNewFileDialect_Encoding(t *testing.T) {
 		{"utf8", "utf8"},
 		{"sjis", "sjis"},
 		{"cp932", "cp932"},
+		{"EUC-JP", "EUC-JP"},
+		{"auto", "auto"},
 	} {
 		d, err := NewFileDialect("", tc.encoding, false)
 		require.Nil(t, err, "NewFileDialect returns error: %v", err)
 		assert.Equal(t, tc.expected, d.Encoding, "for loop index %d", i)
 	}
+	_, err := NewFileDialect("", "ebcdic", false)
+	assert.NotNil(t, err, "unknown encoding should be rejected")
 }
 
 func TestNewFileDialect_Header(t *testing.T) {
@@ -65,3 +69,28 @@ func TestNewFileDialect_Header(t *testing.T) {
 		assert.Equal(t, tc.expected, d.HasHeader, "for loop index %d", i)
 	}
 }
+
+func TestFileDialect_IsNull(t *testing.T) {
+	d, err := NewFileDialect("", "", false)
+	require.Nil(t, err, "NewFileDialect returns error: %v", err)
+	assert.False(t, d.IsNull("NULL"), "no null tokens by default")
+	d.NullValues = []string{"NULL", "NA", `\N`, "なし"}
+	for i, tc := range []struct {
+		s          string
+		ignoreCase bool
+		expected   bool
+	}{
+		{"NULL", false, true},
+		{"null", false, false},
+		{"null", true, true},
+		{"Na", true, true},
+		{`\N`, false, true},
+		{`\n`, true, true},
+		{"なし", false, true},
+		{"N/A", true, false},
+		{"", true, false},
+	} {
+		d.NullIgnoreCase = tc.ignoreCase
+		assert.Equal(t, tc.expected, d.IsNull(tc.s), "for loop index %d", i)
+	}
+}
@@ -0,0 +1,67 @@
+package csvhelper
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// LineCounter is a reader which passes lines of the underlying reader one
+// by one, and counts lines passed. Since csv.Reader does not read ahead of
+// lines it needs, the count is the last line of the record read last.
+type LineCounter struct {
+	r       *bufio.Reader
+	pending []byte
+	err     error
+	lines   int
+	partial bool
+}
+
+// NewLineCounter returns a new LineCounter reading r.
+func NewLineCounter(r io.Reader) *LineCounter {
+	return &LineCounter{r: bufio.NewReader(r)}
+}
+
+func (c *LineCounter) Read(p []byte) (n int, err error) {
+	if len(c.pending) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		c.pending, c.err = c.r.ReadSlice('\n')
+		if c.err == bufio.ErrBufferFull {
+			c.err = nil
+		}
+		if len(c.pending) == 0 {
+			return 0, c.err
+		}
+	}
+	n = copy(p, c.pending)
+	c.lines += bytes.Count(p[:n], []byte{'\n'})
+	c.partial = p[n-1] != '\n'
+	c.pending = c.pending[n:]
+	if len(c.pending) > 0 {
+		// Error is returned after the rest of line.
+		return n, nil
+	}
+	return n, c.err
+}
+
+// Line returns number of lines read, which starts with 1. The last line
+// without newline is counted when it is read partly.
+func (c *LineCounter) Line() int {
+	if c.partial {
+		return c.lines + 1
+	}
+	return c.lines
+}
+
+// RecordLine returns the first line of the record read last, which is
+// given to count newlines in its fields.
+func (c *LineCounter) RecordLine(record []string) int {
+	line := c.Line()
+	for _, field := range record {
+		line -= strings.Count(field, "\n")
+	}
+	return line
+}
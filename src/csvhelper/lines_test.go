@@ -0,0 +1,53 @@
+package csvhelper
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCsvLineReader(t *testing.T) {
+	a := assert.New(t)
+	input := "id,memo\r\n" +
+		"1,a\r\n" +
+		"\r\n" +
+		"# comment\r\n" +
+		"2,\"multi\r\nline\r\nmemo\"\r\n" +
+		"\n" +
+		"3,\"b\""
+	d, err := NewFileDialect("", "", true)
+	require.Nil(t, err)
+	reader, counter := NewCsvLineReader(strings.NewReader(input), d)
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		lines = append(lines, counter.RecordLine(record))
+	}
+	a.Equal([]int{1, 2, 5, 9}, lines)
+	a.Equal(9, counter.Line())
+}
+
+func TestLineCounter_LongLine(t *testing.T) {
+	a := assert.New(t)
+	long := strings.Repeat("x", 10000)
+	counter := NewLineCounter(strings.NewReader(long + "\n" + long + "\n"))
+	b := make([]byte, 3000)
+	total := 0
+	for {
+		n, err := counter.Read(b)
+		total += n
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+	}
+	a.Equal(20002, total)
+	a.Equal(2, counter.Line())
+}
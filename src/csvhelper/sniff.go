@@ -0,0 +1,228 @@
+package csvhelper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// SniffLines is the number of lines inspected by SniffDialect.
+var SniffLines = 50
+
+// sniffSize is the maximum size of prefix inspected by SniffDialect.
+const sniffSize = 64 * 1024
+
+// Quoting styles of fields inferred by SniffDialect.
+const (
+	QuoteAll     = "all"     // all fields are quoted
+	QuoteMinimal = "minimal" // some fields are quoted
+	QuoteNone    = "none"    // no fields are quoted
+)
+
+// sniffDelimiters are candidates of delimiter in order of preference.
+var sniffDelimiters = []rune{',', '\t', ';', '|'}
+
+// SniffDialect infers delimiter, quoting style and header line from the
+// first lines of r in encoding of d. It returns a reader which reads r
+// from the beginning and a copy of d with the inferred ones. Comma and
+// HasHeader of d are kept when they cannot be inferred.
+// The reader is returned even on error, which occurs again on reading it.
+func SniffDialect(r io.Reader, d *FileDialect) (io.Reader, *FileDialect, error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+	prefix, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return br, nil, err
+	}
+	sniffed := *d
+	sniffed.Sniff = false
+	lines := sniffSample(prefix, d.Encoding, err == io.EOF)
+	if len(lines) == 0 {
+		return br, &sniffed, nil
+	}
+	if comma, ok := sniffDelimiter(lines); ok {
+		sniffed.Comma = comma
+	}
+	records := sniffRecords(lines, sniffed.Comma)
+	sniffed.Quoting = sniffQuoting(lines, sniffed.Comma)
+	if sniffed.Quoting == QuoteNone && strings.Contains(strings.Join(lines, ""), `"`) {
+		// Quotes in unquoted fields, such as inch mark.
+		sniffed.LazyQuotes = true
+	}
+	if header, ok := sniffHeader(records); ok {
+		sniffed.HasHeader = header
+	}
+	return br, &sniffed, nil
+}
+
+// sniffSample decodes prefix and splits it into lines up to SniffLines.
+// The last line is dropped unless prefix is whole input, since it may be
+// cut in the middle.
+func sniffSample(prefix []byte, name string, whole bool) []string {
+	e, err := LookupEncoding(name)
+	if err != nil {
+		e = encoding.Nop
+	}
+	// Decoding error only occurs at the end cut in the middle.
+	b, _ := ioutil.ReadAll(transform.NewReader(bytes.NewReader(prefix), e.NewDecoder()))
+	lines := strings.SplitAfter(string(b), "\n")
+	if !whole || lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	sample := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sample = append(sample, line)
+		if len(sample) == SniffLines {
+			break
+		}
+	}
+	return sample
+}
+
+// sniffRecords parses lines by comma. Lines which fail to parse, such as
+// the last line cut in quoted field, are skipped.
+func sniffRecords(lines []string, comma rune) [][]string {
+	reader := csv.NewReader(strings.NewReader(strings.Join(lines, "")))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// sniffDelimiter chooses the candidate which splits records into the
+// same number of fields most consistently. It fails when every candidate
+// splits records into single field.
+func sniffDelimiter(lines []string) (comma rune, ok bool) {
+	bestRatio, bestFields := 0.0, 1
+	for _, c := range sniffDelimiters {
+		records := sniffRecords(lines, c)
+		if len(records) == 0 {
+			continue
+		}
+		counts := make(map[int]int)
+		for _, record := range records {
+			counts[len(record)]++
+		}
+		fields, count := 0, 0
+		for n, c := range counts {
+			if c > count || (c == count && n > fields) {
+				fields, count = n, c
+			}
+		}
+		if fields <= 1 {
+			continue
+		}
+		ratio := float64(count) / float64(len(records))
+		if ratio > bestRatio || (ratio == bestRatio && fields > bestFields) {
+			comma, bestRatio, bestFields = c, ratio, fields
+			ok = true
+		}
+	}
+	return comma, ok
+}
+
+// sniffQuoting returns quoting style of fields, which is judged by quotes
+// at the beginning of fields.
+func sniffQuoting(lines []string, comma rune) string {
+	quoted, total := 0, 0
+	inQuotes, closed, start := false, false, true
+	for _, r := range strings.Join(lines, "") {
+		switch {
+		case inQuotes:
+			if r == '"' {
+				inQuotes, closed = false, true
+				continue
+			}
+		case closed && r == '"':
+			// Escaped quote closes and opens quotes immediately.
+			inQuotes = true
+		case r == comma || r == '\n':
+			start = true
+		case start && r == '"':
+			inQuotes, start = true, false
+			quoted++
+			total++
+		case start && r != ' ' && r != '\r':
+			start = false
+			total++
+		}
+		closed = false
+	}
+	switch {
+	case quoted == 0:
+		return QuoteNone
+	case quoted == total:
+		return QuoteAll
+	}
+	return QuoteMinimal
+}
+
+// sniffHeader infers whether the first record is header by votes of
+// columns. A column votes for header when the first value is not number
+// while others are all numbers, or length of the first value differs
+// while others have the same length. It fails without votes.
+func sniffHeader(records [][]string) (header bool, ok bool) {
+	if len(records) < 2 {
+		return false, false
+	}
+	first, rest := records[0], records[1:]
+	votes := 0
+	for i, name := range first {
+		numeric, length := true, -1
+		for _, record := range rest {
+			if i >= len(record) || record[i] == "" {
+				continue
+			}
+			if _, err := strconv.ParseFloat(record[i], 64); err != nil {
+				numeric = false
+			}
+			n := utf8.RuneCountInString(record[i])
+			if length == -1 {
+				length = n
+			} else if length != n {
+				length = -2
+			}
+		}
+		if length == -1 {
+			// All values are blank.
+			continue
+		}
+		if numeric {
+			if _, err := strconv.ParseFloat(name, 64); err != nil {
+				votes++
+			} else {
+				votes--
+			}
+		} else if length >= 0 {
+			if utf8.RuneCountInString(name) != length {
+				votes++
+			} else {
+				votes--
+			}
+		}
+	}
+	if votes == 0 {
+		return false, false
+	}
+	return votes > 0, true
+}
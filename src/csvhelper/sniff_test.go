@@ -0,0 +1,79 @@
+package csvhelper
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSniffDialect(t *testing.T) {
+	for i, tc := range []struct {
+		input   string
+		comma   rune
+		quoting string
+		header  bool
+	}{
+		{"id,name,price\n1,apple,100\n2,banana,80\n", ',', QuoteNone, true},
+		{"id\tname\tprice\n1\tapple\t100\n2\tbanana\t80\n", '\t', QuoteNone, true},
+		{"id;name;price\n1;apple;1,5\n2;banana;0,8\n", ';', QuoteNone, true},
+		{"id|name|note\n1|apple|a,b,c\n2|banana|d,e\n", '|', QuoteNone, true},
+		{"1,apple,100\n2,banana,80\n3,cherry,500\n", ',', QuoteNone, false},
+		{"\"id\",\"name\"\n\"1\",\"a, b\"\n\"2\",\"c \"\"d, e\"\"\"\n", ',', QuoteAll, true},
+		{"code,name\n01,\"Tokyo, Japan\"\n02,Osaka\n", ',', QuoteMinimal, true},
+		{"コード,都道府県\n01,北海道\n02,青森県\n", ',', QuoteNone, true},
+		{"2016-01-01,晴れ\n2016-01-02,雨\n2016-01-03,曇り\n", ',', QuoteNone, false},
+	} {
+		r, d, err := SniffDialect(strings.NewReader(tc.input), &FileDialect{Comma: 'x', HasHeader: true, Sniff: true})
+		require.Nil(t, err)
+		assert.Equal(t, tc.comma, d.Comma, "delimiter of case #%d", i)
+		assert.Equal(t, tc.quoting, d.Quoting, "quoting of case #%d", i)
+		assert.Equal(t, tc.header, d.HasHeader, "header of case #%d", i)
+		assert.False(t, d.Sniff)
+		b, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.input, string(b), "reader should read from the beginning")
+	}
+}
+
+func TestSniffDialectFallback(t *testing.T) {
+	given := &FileDialect{Comma: '\t', HasHeader: true, Sniff: true}
+	for _, input := range []string{"", "name\nA\nB\n", "name\n"} {
+		_, d, err := SniffDialect(strings.NewReader(input), given)
+		require.Nil(t, err)
+		assert.Equal(t, '\t', d.Comma, "fallback to given delimiter for %q", input)
+		assert.True(t, d.HasHeader, "fallback to given header for %q", input)
+	}
+	assert.True(t, given.Sniff, "given dialect should not be changed")
+}
+
+func TestSniffDialectEncoding(t *testing.T) {
+	e, _ := LookupEncoding("utf-16le")
+	encoded, err := e.NewEncoder().String("コード\t都道府県\n01\t北海道\n02\t青森県\n")
+	require.Nil(t, err)
+	_, d, err := SniffDialect(strings.NewReader(encoded), &FileDialect{Comma: ',', Encoding: "utf-16le"})
+	require.Nil(t, err)
+	assert.Equal(t, '\t', d.Comma)
+	assert.True(t, d.HasHeader)
+}
+
+func TestSniffDialectLines(t *testing.T) {
+	// The last line cut in the middle is not inspected.
+	input := strings.Repeat("a,b,c\n", sniffSize/6) + "d;e;f;g;h;i\n"
+	_, d, err := SniffDialect(strings.NewReader(input), &FileDialect{})
+	require.Nil(t, err)
+	assert.Equal(t, ',', d.Comma)
+	assert.Equal(t, 50, len(sniffSample([]byte(input), "", false)))
+}
+
+func TestSniffLazyQuotes(t *testing.T) {
+	input := "size,name\n15\",display\n17\",monitor\n"
+	_, d, err := SniffDialect(strings.NewReader(input), &FileDialect{})
+	require.Nil(t, err)
+	assert.True(t, d.LazyQuotes)
+	records, err := NewCsvReader(strings.NewReader(input), d).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"15\"", "display"}, records[1])
+}